@@ -0,0 +1,217 @@
+// Package camera follows a target around the world: it lets the target move
+// inside a dead-zone box, eases after it, leads ahead of the way it faces,
+// shakes with trauma from hits and gunfire and eases between zoom levels.
+//
+// Shake uses the trauma model: events add trauma from 0 to 1, which decays
+// over time, and the camera shakes by trauma squared, so small knocks barely
+// register while big ones build up quickly.
+package camera
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Config tunes how the camera follows, shakes and zooms
+type Config struct {
+	DeadZone       rl.Vector2 // Size of the box around the screen center the target moves in without the camera following
+	Smoothing      float32    // How quickly the camera catches up, per second, 0 to snap straight to it
+	LookAhead      float32    // Pixels the camera leads ahead of the way the target faces
+	LookAheadSpeed float32    // How quickly the lead swings round when the target turns, per second
+	MaxShake       float32    // Pixels the camera moves at full trauma
+	MaxShakeAngle  float32    // Degrees the camera tilts at full trauma
+	ShakeFrequency float32    // How fast the shake wobbles, in wobbles per second
+	TraumaDecay    float32    // Trauma lost per second
+	ZoomSpeed      float32    // How quickly zoom changes are eased in, per second
+}
+
+// DefaultConfig is how the game's camera behaves
+func DefaultConfig() Config {
+	return Config{
+		DeadZone:       rl.Vector2{X: 200, Y: 120},
+		Smoothing:      6,
+		LookAhead:      80,
+		LookAheadSpeed: 2,
+		MaxShake:       14,
+		MaxShakeAngle:  2,
+		ShakeFrequency: 18,
+		TraumaDecay:    1.2,
+		ZoomSpeed:      4,
+	}
+}
+
+// Camera follows a target around a world, see the package docs
+type Camera struct {
+	Config
+	screen    rl.Vector2   // Size of the screen in pixels
+	bounds    rl.Rectangle // The camera never shows anything outside these
+	focus     rl.Vector2   // Center of the dead zone, dragged along by the target
+	position  rl.Vector2   // Where the camera looks, before shaking
+	lead      float32      // Current look-ahead, swinging between -LookAhead and LookAhead
+	trauma    float32
+	time      float32 // Seconds since the camera was made, drives the shake
+	zoom      float32
+	zoomGoal  float32
+	shake     rl.Vector2 // Offset from shaking this update
+	shakeTilt float32    // Rotation from shaking this update, in degrees
+}
+
+// New makes a camera for a screen of the given size, showing only what's
+// inside bounds, looking straight at target
+func New(config Config, screenWidth, screenHeight int32, bounds rl.Rectangle, target rl.Vector2) *Camera {
+	c := &Camera{
+		Config:   config,
+		screen:   rl.Vector2{X: float32(screenWidth), Y: float32(screenHeight)},
+		bounds:   bounds,
+		zoom:     1,
+		zoomGoal: 1,
+	}
+	c.SnapTo(target)
+	return c
+}
+
+// SnapTo centers the camera on target at once, with no easing or look-ahead,
+// for starting a level or loading a save
+func (c *Camera) SnapTo(target rl.Vector2) {
+	c.focus = target
+	c.lead = 0
+	c.position = c.clamp(target)
+}
+
+// Follow moves the camera after target, which faces right or left, over dt seconds
+func (c *Camera) Follow(target rl.Vector2, facingRight bool, dt float32) {
+	c.time += dt
+
+	// Ease toward the zoom being asked for
+	c.zoom += (c.zoomGoal - c.zoom) * ease(c.ZoomSpeed, dt)
+
+	// The dead zone only moves once the target pushes against its edges
+	half := rl.Vector2Scale(c.DeadZone, 0.5/c.zoom)
+	c.focus.X = clampFloat(c.focus.X, target.X-half.X, target.X+half.X)
+	c.focus.Y = clampFloat(c.focus.Y, target.Y-half.Y, target.Y+half.Y)
+
+	leadGoal := c.LookAhead
+	if !facingRight {
+		leadGoal = -leadGoal
+	}
+	c.lead += (leadGoal - c.lead) * ease(c.LookAheadSpeed, dt)
+
+	goal := c.clamp(rl.Vector2{X: c.focus.X + c.lead, Y: c.focus.Y})
+	if c.Smoothing <= 0 {
+		c.position = goal
+	} else {
+		c.position = rl.Vector2Lerp(c.position, goal, ease(c.Smoothing, dt))
+	}
+	c.position = c.clamp(c.position) // Zooming out can show past the edges before the easing catches up
+
+	// Shake by trauma squared, along smooth wobbles rather than jumping about every frame
+	c.trauma = max(c.trauma-c.TraumaDecay*dt, 0)
+	shake := c.trauma * c.trauma
+	c.shake = rl.Vector2{
+		X: c.MaxShake * shake * wobble(c.time*c.ShakeFrequency, 0),
+		Y: c.MaxShake * shake * wobble(c.time*c.ShakeFrequency, 10),
+	}
+	c.shakeTilt = c.MaxShakeAngle * shake * wobble(c.time*c.ShakeFrequency, 20)
+}
+
+// AddTrauma shakes the camera, amount from 0 for nothing to 1 for as hard as it goes
+func (c *Camera) AddTrauma(amount float32) {
+	c.trauma = min(c.trauma+amount, 1)
+}
+
+// Trauma returns how hard the camera is shaking, from 0 to 1
+func (c *Camera) Trauma() float32 {
+	return c.trauma
+}
+
+// ZoomTo eases the camera to zoom, 1 for no zoom and above 1 to zoom in
+func (c *Camera) ZoomTo(zoom float32) {
+	if zoom > 0 {
+		c.zoomGoal = zoom
+	}
+}
+
+// SetZoom changes the zoom at once, with no easing
+func (c *Camera) SetZoom(zoom float32) {
+	if zoom > 0 {
+		c.zoom, c.zoomGoal = zoom, zoom
+		c.position = c.clamp(c.position)
+	}
+}
+
+// Zoom returns the camera's current zoom
+func (c *Camera) Zoom() float32 {
+	return c.zoom
+}
+
+// Camera2D returns the raylib camera to draw the world with, shake included
+func (c *Camera) Camera2D() rl.Camera2D {
+	return rl.Camera2D{
+		Offset:   rl.Vector2Scale(c.screen, 0.5),
+		Target:   rl.Vector2Add(c.position, c.shake),
+		Rotation: c.shakeTilt,
+		Zoom:     c.zoom,
+	}
+}
+
+// View returns the part of the world on screen, with room for the shake, for
+// culling and spawning out of sight
+func (c *Camera) View() rl.Rectangle {
+	size := rl.Vector2Scale(c.screen, 1/c.zoom)
+	margin := c.MaxShake * c.trauma * c.trauma
+	if c.shakeTilt != 0 {
+		margin += (size.X + size.Y) / 2 * float32(math.Sin(float64(abs(c.shakeTilt))*math.Pi/180))
+	}
+	return rl.Rectangle{
+		X:      c.position.X - size.X/2 - margin,
+		Y:      c.position.Y - size.Y/2 - margin,
+		Width:  size.X + 2*margin,
+		Height: size.Y + 2*margin,
+	}
+}
+
+// ScreenToWorld returns the point in the world under a point on screen
+func (c *Camera) ScreenToWorld(point rl.Vector2) rl.Vector2 {
+	return rl.GetScreenToWorld2D(point, c.Camera2D())
+}
+
+// clamp keeps a camera position far enough from the edges of bounds that
+// nothing outside them shows. A world smaller than the screen is centered.
+func (c *Camera) clamp(position rl.Vector2) rl.Vector2 {
+	half := rl.Vector2Scale(c.screen, 0.5/c.zoom)
+	return rl.Vector2{
+		X: clampAxis(position.X, c.bounds.X, c.bounds.Width, half.X),
+		Y: clampAxis(position.Y, c.bounds.Y, c.bounds.Height, half.Y),
+	}
+}
+
+func clampAxis(value, start, length, half float32) float32 {
+	if length <= 2*half {
+		return start + length/2
+	}
+	return clampFloat(value, start+half, start+length-half)
+}
+
+// ease returns how much of the way to close over dt seconds when closing the
+// gap at rate per second, the same however dt is sliced
+func ease(rate, dt float32) float32 {
+	return 1 - float32(math.Exp(float64(-rate*dt)))
+}
+
+// wobble is smooth noise from -1 to 1, seed picks an unrelated wobble
+func wobble(t, seed float32) float32 {
+	x := float64(t + seed)
+	return float32(math.Sin(x)*0.6 + math.Sin(x*2.3+1.7)*0.3 + math.Sin(x*4.1+0.3)*0.1)
+}
+
+func clampFloat(value, low, high float32) float32 {
+	return max(low, min(value, high))
+}
+
+func abs(x float32) float32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -2,220 +2,346 @@ package core
 
 import (
 	"fmt"
+	"log"
 	"math/rand"
-	"time"
 
+	"platformer-game/camera"
 	"platformer-game/gameobjects"
+	"platformer-game/input"
+	"platformer-game/level"
+	"platformer-game/resources"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// DefaultLevel is the map a new run starts on
+const DefaultLevel = "assets/levels/level1.json"
+
 var (
-	camera     rl.Camera2D
-	background rl.Texture2D
-	zombies    []*gameobjects.Zombie // Slice to hold pointers to all zombies
+	cam          *camera.Camera // Follows the player around the level
+	currentLevel *level.Level
+	zombies      []*gameobjects.Zombie // Slice to hold pointers to all zombies
+	worldItems   gameobjects.WorldItems
+	aimWithMouse = true // The gun follows the mouse until the right stick is used
+
+	// World size in pixels, taken from the loaded level
+	worldWidth  int
+	worldHeight int
 )
 
 const (
-	worldWidth   = 5000
-	worldHeight  = 1200
 	screenWidth  = 800
 	screenHeight = 450
 )
 
 const (
-	miniMapWidth  = 200
-	miniMapHeight = 150
-	miniMapX      = screenWidth - miniMapWidth - 10
-	miniMapY      = 10
-	deadZoneWidth = 200
-)
-var (
-	testItem gameobjects.WorldItem
+	pickupReach   = 70   // How close the player's center has to be to an item's to pick it up
+	lootToss      = 120  // Loot flies up to this fast sideways out of a corpse, in pixels per second
+	lootPop       = 300  // Upward speed loot flies out of a corpse at, in pixels per second
+	gunfireTrauma = 0.12 // Camera shake from each shot, see the camera package
+	hurtTrauma    = 0.05 // Camera shake from each point of health the player loses
+	runZoom       = 0.9  // Share of the level's zoom while running, to see further ahead
 )
 
-func InitGame(worldWidth, worldHeight int) {
-	background = rl.LoadTexture("assets/levelonebg.png")
+// itemTypes maps the itemType property of level item placements to item types
+var itemTypes = map[string]gameobjects.ItemType{
+	"weapon":     gameobjects.Weapon,
+	"healthpack": gameobjects.HealthPack,
+	"consumable": gameobjects.Consumable,
+	"ammo":       gameobjects.Ammo,
+	"currency":   gameobjects.Currency,
+	"other":      gameobjects.Other,
+}
+
+// InitGame loads a level and everything a run on it needs, it can be called
+// again after UnloadGame to restart
+func InitGame(levelPath string) error {
+	lvl, err := level.Load(levelPath)
+	if err != nil {
+		return err
+	}
+	currentLevel = lvl
+	worldWidth, worldHeight = lvl.Width, lvl.Height
+	zombies = nil
+	gameobjects.InitEffects()
 
 	// Initializing  player
-	gameobjects.InitPlayer(worldWidth, worldHeight)
+	if err := gameobjects.InitPlayer(worldWidth, worldHeight); err != nil {
+		return err
+	}
+	if lvl.HasPlayerSpawn {
+		gameobjects.PlayerInstance.Position = lvl.PlayerSpawn
+	}
 
 	// Initializing zombies
-	initZombies(5) // Spawning 5 zombies
+	if err := initZombies(lvl.ZombieSpawns); err != nil {
+		return err
+	}
+
+	// Initializing the waves that keep zombies coming
+	wavesPath := DefaultWaves
+	if lvl.Waves != "" {
+		wavesPath = lvl.Waves
+	}
+	config, err := LoadWaves(wavesPath)
+	if err != nil {
+		return err
+	}
+	waves = newSpawner(config)
 
 	// Initializing camera
-	camera = rl.Camera2D{
-		Target: gameobjects.PlayerInstance.Position,
-		Offset: rl.NewVector2(float32(screenWidth)/2, float32(screenHeight)/2),
-		Zoom:   1.0,
+	bounds := rl.Rectangle{Width: float32(worldWidth), Height: float32(worldHeight)}
+	cam = camera.New(camera.DefaultConfig(), screenWidth, screenHeight, bounds, gameobjects.PlayerInstance.Position)
+	cam.SetZoom(lvl.Zoom)
+	minimap.load(lvl)
+	initHUD()
+
+	for _, placement := range lvl.Items {
+		itemType, ok := itemTypes[placement.ItemType]
+		if !ok {
+			return fmt.Errorf("%s: item %q has unknown itemType %q", levelPath, placement.Name, placement.ItemType)
+		}
+		worldItems.Spawn(gameobjects.NewWorldItem(placement.Position.X, placement.Position.Y, placement.ID, itemType, placement.Name, placement.Texture, placement.Count))
+	}
+	return nil
+}
+
+// levelCleared reports whether every wave is over and no zombie is left, and
+// the player has made it to an exit if the level has any
+func levelCleared() bool {
+	if !waves.done || len(zombies) > 0 {
+		return false
+	}
+	if len(currentLevel.Exits) == 0 {
+		return true
 	}
+	hitbox := gameobjects.PlayerInstance.Hitbox()
+	for _, exit := range currentLevel.Exits {
+		if rl.CheckCollisionRecs(hitbox, exit) {
+			return true
+		}
+	}
+	return false
+}
 
-	testItem = gameobjects.NewWorldItem(110, 1040, gameobjects.Weapon, "Sword", "assets/sword.png")
+// UnloadGame frees the level, textures and sounds loaded by InitGame
+func UnloadGame() {
+	if currentLevel != nil {
+		currentLevel.Unload()
+		currentLevel = nil
+	}
 
+	// World items and inventory slots each hold their own texture reference
+	worldItems.Clear()
+	gameobjects.UnloadEffects()
+	minimap.unload()
+	if left := gameobjects.PlayerInstance.Inventory.CancelDrag(); !left.Empty() {
+		resources.ReleaseTexture(left.Image)
+	}
+	for _, item := range gameobjects.PlayerInstance.Inventory.Slots {
+		if item.Image.ID != 0 {
+			resources.ReleaseTexture(item.Image)
+		}
+	}
+	gameobjects.PlayerInstance.Unload()
+	gameobjects.PlayerInstance = gameobjects.Player{} // A run that failed to start mustn't unload the last one's player again
 
+	for _, zombie := range zombies {
+		zombie.Unload()
+	}
+	zombies = nil
 }
 
-// Initializing zombies with random positions
-func initZombies(numZombies int) {
-	rand.Seed(time.Now().UnixNano())
-	for i := 0; i < numZombies; i++ {
-		// Randomize position within world boundaries
-		x := float32(rand.Intn(worldWidth-100) + 50) // Keep zombies within world bounds
-		y := float32(worldHeight - 50)               // Spawn zombies at ground level
-
-		// Initialize zombie with random position and append to the zombies slice
-		zombie := gameobjects.InitZombie(x, y-50, 1)
+// Initializing zombies at the level's spawn points
+func initZombies(spawns []level.ZombieSpawn) error {
+	for _, spawn := range spawns {
+		zombie, err := gameobjects.InitZombie(spawn.Position.X, spawn.Position.Y, spawn.ZombieType)
+		if err != nil {
+			return err
+		}
 		zombies = append(zombies, &zombie)
 	}
+	return nil
+}
+
+// pickupItem returns the world item the player would pick up right now, if any
+func pickupItem() *gameobjects.WorldItem {
+	return worldItems.Nearest(gameobjects.PlayerInstance.Position, pickupReach)
+}
+
+// tryPickup adds the closest world item in reach to the inventory
+func tryPickup() {
+	worldItem := pickupItem()
+	if worldItem == nil {
+		return
+	}
+
+	if gameobjects.PlayerInstance.Collect(worldItem.Item()) {
+		worldItems.Despawn(worldItem)
+		return
+	}
+	left := gameobjects.PlayerInstance.Inventory.AddItem(worldItem.Item())
+	switch {
+	case left == 0:
+		worldItems.Despawn(worldItem) // Remove from game world
+	case left < worldItem.Count:
+		showNotice(fmt.Sprintf("Picked up %d %s, inventory is full", worldItem.Count-left, worldItem.Name))
+		worldItem.Count = left // The rest stays on the ground
+	default:
+		showNotice("Inventory is full")
+	}
+}
+
+// dropItem puts a stack taken out of the inventory back into the world at the
+// player's feet, releasing the stack's own texture reference
+func dropItem(item gameobjects.Item) {
+	if item.Empty() {
+		return
+	}
+	player := &gameobjects.PlayerInstance
+	feet := rl.Vector2{
+		X: player.Position.X - float32(item.Image.Width)/2,
+		Y: player.Position.Y + player.Height/2 - float32(item.Image.Height),
+	}
+	worldItems.Spawn(gameobjects.NewWorldItem(feet.X, feet.Y, item.ID, item.Type, item.Name, item.TexturePath, item.Count))
+	resources.ReleaseTexture(item.Image)
+}
+
+// dropLoot rolls a dead zombie's loot table and throws the drops out of its corpse
+func dropLoot(zombie *gameobjects.Zombie) {
+	for _, drop := range zombie.Archetype.Loot.Roll() {
+		def, ok := gameobjects.ItemDefFor(drop.Item)
+		if !ok {
+			log.Printf("Unknown loot item %q", drop.Item) // Loot tables are checked when archetypes are registered, so this is a bug
+			continue
+		}
+		item := gameobjects.NewWorldItemFromDef(def, zombie.Position, drop.Count)
+		item.Velocity = rl.Vector2{X: float32(rand.Intn(2*lootToss+1) - lootToss), Y: -lootPop}
+		worldItems.Spawn(item)
+	}
+}
+
+// updateAim points the player's gun at the mouse cursor, or along the right
+// stick. After using the stick the gun fires straight ahead while it's let go,
+// until the mouse moves again.
+func updateAim() {
+	player := &gameobjects.PlayerInstance
+	if stick, ok := input.AimStick(); ok {
+		aimWithMouse = false
+		player.Aim = stick
+		return
+	}
+	if rl.Vector2Length(rl.GetMouseDelta()) > 0 {
+		aimWithMouse = true
+	}
+	if !aimWithMouse {
+		player.Aim = rl.Vector2{}
+		return
+	}
+	player.AimAt(cam.ScreenToWorld(rl.GetMousePosition()))
 }
 
-func UpdateGame(worldHeight int) {
-	gameobjects.PlayerInstance.Inventory.UpdateSelection()
-
-	// Toggle inventory display with 'I' key
-	if rl.IsKeyPressed(rl.KeyI) {
-		gameobjects.PlayerInstance.Inventory.IsOpen = !gameobjects.PlayerInstance.Inventory.IsOpen
-	}
-
-	gameobjects.PlayerInstance.UpdateHeldItem()
-	// Check for item pickup with "E" key
-    if rl.IsKeyPressed(rl.KeyE) {
-        playerPosition := gameobjects.PlayerInstance.Position
-        itemPosition := testItem.Position
-        distance := rl.Vector2Distance(playerPosition, itemPosition)
-
-        // If within range, try adding item to inventory
-        if distance < 50 { // Adjust as needed
-            item := gameobjects.Item{
-                Type:  testItem.Type,
-                Name:  testItem.Name,
-                Image: testItem.Texture,
-            }
-            if gameobjects.PlayerInstance.Inventory.AddItem(item) {
-                fmt.Println("Item added to inventory:", testItem.Name)
-                testItem.Texture.ID = 0 // Remove from game world
-            } else {
-                fmt.Println("Inventory is full!")
-            }
-
-            // Debug: Print out inventory contents
-            fmt.Println("Current Inventory:")
-            for i, slot := range gameobjects.PlayerInstance.Inventory.Slots {
-                fmt.Printf("Slot %d: %s\n", i, slot.Name)
-            }
-        }
-    }
-	
+// UpdateGame advances the simulation by one fixed step of dt seconds
+func UpdateGame(dt float32) {
+	updateAim()
+	health := gameobjects.PlayerInstance.Health
 	// Updating player and call Shoot to check for zombie hits
-	gameobjects.PlayerInstance.Update(worldHeight, worldWidth, zombies)
-	gameobjects.PlayerInstance.Shoot() // Call Shoot to check for zombie hits
+	gameobjects.PlayerInstance.Update(dt, currentLevel.Collision, zombies)
+	shots := len(gameobjects.PlayerInstance.Bullets)
+	gameobjects.PlayerInstance.Shoot(currentLevel.Collision) // Call Shoot to check for zombie hits
+	if len(gameobjects.PlayerInstance.Bullets) > shots {
+		cam.AddTrauma(gunfireTrauma)
+	}
 
 	playerPosition := gameobjects.PlayerInstance.Position
 
+	currentLevel.Update(dt)
+	waves.Update(dt)
+	worldItems.Update(dt, currentLevel.Collision)
+	gameobjects.Impacts.Update(dt)
+	gameobjects.Particles.Update(dt)
+
 	// Updating each zombie in the zombies slice
 	for i := len(zombies) - 1; i >= 0; i-- {
-		zombies[i].Update(worldWidth, playerPosition)
-		if !zombies[i].IsAlive && zombies[i].State == gameobjects.ZombieDead && zombies[i].CurrentFrame == len(zombies[i].DeadFrames)-1 {
-			zombies[i].UnloadSounds() // Unload zombie sounds once dead
+		zombies[i].Update(dt, currentLevel.Collision, playerPosition)
+		if zombies[i].DeathFinished() {
+			dropLoot(zombies[i])
+			zombies[i].Unload() // Free zombie textures and sounds once dead
 			// Remove zombie once dead animation completes
 			zombies = append(zombies[:i], zombies[i+1:]...)
 		}
 	}
 
-	// For more smooth camera transition using dead zones
-	playerX := gameobjects.PlayerInstance.Position.X
-	if playerX > camera.Target.X+float32(screenWidth)/2-deadZoneWidth {
-		camera.Target.X = playerX - float32(screenWidth)/2 + deadZoneWidth
-	} else if playerX < camera.Target.X-float32(screenWidth)/2+deadZoneWidth {
-		camera.Target.X = playerX + float32(screenWidth)/2 - deadZoneWidth
+	if lost := health - gameobjects.PlayerInstance.Health; lost > 0 {
+		cam.AddTrauma(float32(lost) * hurtTrauma)
 	}
-
-	// Keeping camera within world bounds
-	camera.Target.X = clampFloat(camera.Target.X, float32(screenWidth)/2, float32(worldWidth)-float32(screenWidth)/2)
-	camera.Target.Y = clampFloat(camera.Target.Y, float32(screenHeight)/2, float32(worldHeight)-float32(screenHeight)/2)
+	updateCamera(dt)
+	updateHUD(dt)
+
+	// Whatever comes on screen shows up on the map
+	view := cam.View()
+	minimap.reveal(rl.Rectangle{
+		X:      view.X - revealMargin,
+		Y:      view.Y - revealMargin,
+		Width:  view.Width + 2*revealMargin,
+		Height: view.Height + 2*revealMargin,
+	})
 }
 
-func DrawMiniMap() {
-	rl.DrawRectangle(miniMapX, miniMapY, miniMapWidth, miniMapHeight, rl.LightGray)
-
-	// Calculate scaling factors for mini-map
-	scaleX := float32(miniMapWidth) / float32(worldWidth)
-	scaleY := float32(miniMapHeight) / float32(worldHeight)
-
-	// Drawing world boundary on mini-map
-	rl.DrawRectangleLines(miniMapX, miniMapY, miniMapWidth, miniMapHeight, rl.DarkGray)
-
-	// Drawing camera view on mini-map
-	viewX := miniMapX + int((camera.Target.X-float32(screenWidth)/2)*scaleX)
-	viewY := miniMapY + int((camera.Target.Y-float32(screenHeight)/2)*scaleY)
-	viewWidth := int(float32(screenWidth) * scaleX * 0.8)
-	viewHeight := int(float32(screenHeight) * scaleY * 0.8)
-
-	viewX = clamp(viewX, miniMapX, miniMapX+miniMapWidth-viewWidth)
-	viewY = clamp(viewY, miniMapY, miniMapY+miniMapHeight-viewHeight)
-	rl.DrawRectangleLines(int32(viewX), int32(viewY), int32(viewWidth), int32(viewHeight), rl.Red)
+// updateCamera follows the player, zooming out a little while they run
+func updateCamera(dt float32) {
+	player := &gameobjects.PlayerInstance
+	zoom := currentLevel.Zoom
+	if player.State == gameobjects.Running {
+		zoom *= runZoom
+	}
+	cam.ZoomTo(zoom)
+	cam.Follow(player.Position, player.FacingRight, dt)
 }
 
+// DrawGame draws the world and its overlays, the scene stack handles BeginDrawing/EndDrawing
 func DrawGame() {
-	rl.BeginDrawing()
-	rl.ClearBackground(rl.RayWhite)
-
 	// Drawing game world with camera
-	rl.BeginMode2D(camera)
-	rl.DrawTexture(background, 0, 0, rl.White)
-	testItem.Draw() // Ensure this line is here
+	rl.BeginMode2D(cam.Camera2D())
+	currentLevel.Draw(cameraView())
+	gameobjects.Impacts.Draw(cameraView())
+	worldItems.Draw(cameraView(), pickupItem())
 	gameobjects.PlayerInstance.Draw()
 
 	// Draw each zombie in the zombies slice
 	for _, zombie := range zombies {
 		zombie.Draw()
 	}
+	gameobjects.Particles.Draw(cameraView())
 	rl.EndMode2D()
 
 	// Draw inventory if open
-    if gameobjects.PlayerInstance.Inventory.IsOpen {
-        gameobjects.PlayerInstance.Inventory.DrawInventory()
-    }
-
-	// Draw the item in the game world only if it's not picked up
-	if testItem.Texture.ID != 0 {
-		testItem.Draw()
+	if gameobjects.PlayerInstance.Inventory.IsOpen {
+		gameobjects.PlayerInstance.Inventory.DrawInventory()
 	}
 
-	DrawPlayerHealthBar()
-
+	gameHUD.Draw()
 	DrawMiniMap()
-
-	rl.EndDrawing()
+	drawCrosshair()
 }
 
+// drawCrosshair marks where the mouse is aiming, unless the mouse belongs to
+// the inventory
+func drawCrosshair() {
+	if !aimWithMouse || gameobjects.PlayerInstance.Inventory.IsOpen {
+		return
+	}
+	mouse := rl.GetMousePosition()
+	rl.DrawCircleLinesV(mouse, 8, rl.Red)
+	rl.DrawLineV(rl.Vector2{X: mouse.X - 12, Y: mouse.Y}, rl.Vector2{X: mouse.X - 4, Y: mouse.Y}, rl.Red)
+	rl.DrawLineV(rl.Vector2{X: mouse.X + 4, Y: mouse.Y}, rl.Vector2{X: mouse.X + 12, Y: mouse.Y}, rl.Red)
+	rl.DrawLineV(rl.Vector2{X: mouse.X, Y: mouse.Y - 12}, rl.Vector2{X: mouse.X, Y: mouse.Y - 4}, rl.Red)
+	rl.DrawLineV(rl.Vector2{X: mouse.X, Y: mouse.Y + 4}, rl.Vector2{X: mouse.X, Y: mouse.Y + 12}, rl.Red)
+}
 
-
-func DrawPlayerHealthBar() {
-	player := &gameobjects.PlayerInstance
-	healthBarWidth := 200.0
-	healthBarHeight := 20.0
-	healthPercent := player.Health / player.MaxHealth
-
-	// Background of health bar
-	rl.DrawRectangle(
-		20, 20, // Fixed position in top-left corner of screen
-		int32(healthBarWidth),
-		int32(healthBarHeight),
-		rl.DarkGray,
-	)
-
-	// Actual health level
-	rl.DrawRectangle(
-		20, 20,
-		int32(healthBarWidth*healthPercent),
-		int32(healthBarHeight),
-		rl.Red,
-	)
-
-	// Optional: Add health text on the bar
-	healthText := fmt.Sprintf("Health: %.0f/%.0f", player.Health, player.MaxHealth)
-	rl.DrawText(healthText, 30, 25, 10, rl.White)
+// cameraView returns the part of the world currently on screen
+func cameraView() rl.Rectangle {
+	return cam.View()
 }
 
 // Utility function to clamp an integer within a range
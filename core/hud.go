@@ -0,0 +1,128 @@
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"platformer-game/gameobjects"
+	"platformer-game/hud"
+	"platformer-game/input"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// The HUD of the running level and the widgets on it, made by initHUD
+var (
+	gameHUD     *hud.HUD
+	healthBar   *hud.Bar
+	ammoLabel   *hud.Label
+	coinLabel   *hud.Label
+	waveLabel   *hud.Label
+	scoreLabel  *hud.Label
+	buffList    *hud.List
+	heldIcon    *hud.Icon
+	pickupHint  *hud.Prompt
+	waveElement *hud.Element
+	heldElement *hud.Element
+)
+
+// initHUD lays out the HUD for a new run
+func initHUD() {
+	gameHUD = hud.New(screenWidth, screenHeight)
+
+	healthBar = hud.NewBar(200, 20, rl.Red)
+	gameHUD.Add(healthBar, hud.TopLeft, rl.Vector2{X: 20, Y: 20})
+	coinLabel = hud.NewLabel(rl.Gold)
+	gameHUD.Add(coinLabel, hud.TopLeft, rl.Vector2{X: 230, Y: 25})
+	ammoLabel = hud.NewLabel(rl.White)
+	gameHUD.Add(ammoLabel, hud.TopLeft, rl.Vector2{X: 20, Y: 52})
+	buffList = hud.NewList()
+	gameHUD.Add(buffList, hud.TopLeft, rl.Vector2{X: 20, Y: 70})
+
+	waveLabel = hud.NewLabel(rl.White)
+	waveLabel.FontSize = 20
+	waveElement = gameHUD.Add(waveLabel, hud.Top, rl.Vector2{Y: 20})
+	scoreLabel = hud.NewLabel(rl.RayWhite)
+	gameHUD.Add(scoreLabel, hud.Top, rl.Vector2{Y: 44})
+
+	heldIcon = hud.NewIcon(40)
+	heldElement = gameHUD.Add(heldIcon, hud.BottomLeft, rl.Vector2{X: 20, Y: 20})
+	pickupHint = hud.NewPrompt(rl.White)
+	gameHUD.Add(pickupHint, hud.Bottom, rl.Vector2{Y: 70})
+}
+
+// updateHUD hands the HUD what it shows from the state of the game, then
+// animates it over dt seconds
+func updateHUD(dt float32) {
+	player := &gameobjects.PlayerInstance
+
+	healthBar.Set(float32(player.Health), float32(player.MaxHealth))
+	healthBar.Label = fmt.Sprintf("Health: %.0f/%.0f", player.Health, player.MaxHealth)
+	coinLabel.Text = fmt.Sprintf("Coins: %d", player.Coins)
+	updateAmmoLabel()
+
+	buffList.Lines = buffList.Lines[:0]
+	for _, buff := range player.Buffs {
+		buffList.Lines = append(buffList.Lines, hud.Line{
+			Text:     fmt.Sprintf("%s %ds", buff.Name, int(math.Ceil(float64(buff.Remaining)))),
+			Color:    rl.SkyBlue,
+			Progress: buff.Remaining / buff.Duration,
+		})
+	}
+
+	waveLabel.Text = waveText()
+	waveElement.Hidden = waveLabel.Text == ""
+	scoreLabel.Text = fmt.Sprintf("Score: %d", player.Score)
+
+	heldElement.Hidden = player.HeldItem.Empty()
+	heldIcon.Texture = player.HeldItem.Image
+	heldIcon.Caption = player.HeldItem.Name
+	if player.HeldItem.Count > 1 {
+		heldIcon.Caption = fmt.Sprintf("%s x%d", player.HeldItem.Name, player.HeldItem.Count)
+	}
+
+	if item := pickupItem(); item != nil {
+		pickupHint.Show(fmt.Sprintf("Press %s to pick up %s", actionHint(input.Interact), item.Name))
+	}
+
+	gameHUD.Update(dt)
+}
+
+// updateAmmoLabel shows the active gun's ammo, or how far along a reload is
+func updateAmmoLabel() {
+	ammoLabel.Progress = 0
+	ammoLabel.Color = rl.White
+	if melee := gameobjects.PlayerInstance.Melee; melee != nil {
+		ammoLabel.Text = melee.Name
+		return
+	}
+	gun := gameobjects.PlayerInstance.Gun
+	switch {
+	case gun == nil:
+		ammoLabel.Text = ""
+	case gun.Reloading():
+		ammoLabel.Text = gun.Def.Name + " - Reloading..."
+		ammoLabel.Progress = gun.ReloadProgress()
+	default:
+		ammoLabel.Text = fmt.Sprintf("%s  %d/%d", gun.Def.Name, gun.Ammo, gun.Reserve)
+		if gun.Ammo == 0 {
+			ammoLabel.Color = rl.Red
+		}
+	}
+}
+
+// actionHint names what to press for an action, a gamepad button while a
+// gamepad is plugged in and a key or mouse button otherwise
+func actionHint(action input.Action) string {
+	bound := input.Bound(action)
+	for _, b := range bound {
+		onPad := b.Device == input.Gamepad || b.Device == input.PadAxis
+		if onPad == input.GamepadConnected() {
+			return b.String()
+		}
+	}
+	if len(bound) > 0 {
+		return bound[0].String()
+	}
+	return action.Label()
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"platformer-game/gameobjects"
+	"platformer-game/input"
+)
+
+// DefaultTickRate is the number of fixed simulation steps run per second
+const DefaultTickRate = 60
+
+// maxFrameTime caps how much real time a single frame can feed into the
+// simulation, so a long stall (dragging the window, a breakpoint) doesn't
+// turn into hundreds of catch-up updates
+const maxFrameTime = 0.25
+
+var (
+	tickRate    = DefaultTickRate
+	accumulator float32 // Real time not yet consumed by fixed updates
+)
+
+// SetTickRate changes how many simulation steps run per second
+func SetTickRate(rate int) {
+	if rate <= 0 {
+		rate = DefaultTickRate
+	}
+	tickRate = rate
+}
+
+// TickRate returns the current number of simulation steps per second
+func TickRate() int {
+	return tickRate
+}
+
+// Step feeds frameTime seconds of real time into the simulation and runs as
+// many fixed-size updates of the current scene as fit. Leftover time carries
+// over to the next frame, so the game plays the same on a 60Hz and a 240Hz
+// display.
+func Step(frameTime float32) {
+	// Edge-triggered input is sampled every rendered frame so presses aren't
+	// lost on frames where no simulation step runs
+	input.Update()
+	if event, ok := input.GamepadChanged(); ok {
+		gamepadChanged(event)
+	}
+	if scene := CurrentScene(); scene != nil {
+		scene.HandleInput()
+	}
+
+	if frameTime > maxFrameTime {
+		frameTime = maxFrameTime
+	}
+	accumulator += frameTime
+
+	dt := 1 / float32(tickRate)
+	for accumulator >= dt {
+		// Looked up every step since an update may push or pop a scene
+		if scene := CurrentScene(); scene != nil {
+			scene.Update(dt)
+		}
+		accumulator -= dt
+	}
+}
+
+// gamepadChanged lets the player know a gamepad was plugged in or out, and
+// pauses a running game when it's unplugged
+func gamepadChanged(event input.GamepadEvent) {
+	if event.Connected {
+		showNotice("Controller connected: " + event.Name)
+		return
+	}
+	showNotice("Controller disconnected")
+	if _, playing := CurrentScene().(*PlayingScene); playing {
+		PushScene(NewPausedScene())
+	}
+}
+
+// handleGameInput processes per-frame input of a running game that isn't part
+// of the fixed-step simulation, like the inventory and pickups
+func handleGameInput() {
+	player := &gameobjects.PlayerInstance
+	player.Inventory.UpdateSelection()
+
+	// Toggle inventory display, anything still on the mouse goes back in
+	// when it closes
+	if input.Pressed(input.ToggleInventory) {
+		player.Inventory.IsOpen = !player.Inventory.IsOpen
+		if !player.Inventory.IsOpen {
+			dropItem(player.Inventory.CancelDrag())
+		}
+	}
+	if player.Inventory.IsOpen {
+		dropItem(player.Inventory.UpdateMouse())
+	}
+
+	if input.Pressed(input.ToggleMap) {
+		minimap.fullScreen = !minimap.fullScreen
+	}
+
+	// Use the selected item
+	if input.Pressed(input.UseItem) {
+		player.UseSelectedItem()
+	}
+
+	player.UpdateHeldItem()
+	// Check for item pickup
+	if input.Pressed(input.Interact) {
+		tryPickup()
+	}
+}
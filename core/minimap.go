@@ -0,0 +1,217 @@
+package core
+
+import (
+	"platformer-game/gameobjects"
+	"platformer-game/input"
+	"platformer-game/level"
+	"platformer-game/physics"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	miniMapWidth  = 200
+	miniMapHeight = 150
+	miniMapX      = screenWidth - miniMapWidth - 10
+	miniMapY      = 10
+	miniMapScale  = 0.1 // Corner map pixels per world pixel
+	fullMapMargin = 40  // Pixels kept clear around the full-screen map
+	revealMargin  = 150 // World pixels past the edges of the view that count as seen
+)
+
+var (
+	mapBackground = rl.Color{R: 20, G: 24, B: 32, A: 220}
+	mapSolid      = rl.Color{R: 150, G: 140, B: 120, A: 255}
+	mapOneWay     = rl.Color{R: 110, G: 170, B: 110, A: 255}
+	mapFog        = rl.Color{R: 8, G: 8, B: 12, A: 255}
+)
+
+// zombieMarkers colors zombies on the map by what they're doing
+var zombieMarkers = map[gameobjects.ZombieState]rl.Color{
+	gameobjects.ZombieIdle:      rl.LightGray,
+	gameobjects.ZombieWalking:   rl.Orange,
+	gameobjects.ZombieAttacking: rl.Red,
+	gameobjects.ZombieHurt:      rl.Yellow,
+}
+
+// miniMap shows the level from above, in the corner of the screen or over all
+// of it. Only tiles the player has had on screen are shown, the rest is fog.
+type miniMap struct {
+	world      *physics.World
+	exits      []rl.Rectangle
+	terrain    rl.Texture2D // One pixel per tile
+	fog        rl.Texture2D // One pixel per tile, clear where explored
+	fogPixels  []rl.Color
+	explored   []bool // Per tile, row by row
+	fogChanged bool
+	fullScreen bool
+}
+
+// minimap is the map of the running level
+var minimap miniMap
+
+// load draws lvl's collision tiles into the terrain texture and covers
+// everything in fog
+func (m *miniMap) load(lvl *level.Level) {
+	m.world = lvl.Collision
+	m.exits = lvl.Exits
+	m.fullScreen = false
+	columns, rows := m.world.Columns, m.world.Rows
+
+	terrain := make([]rl.Color, columns*rows)
+	for i, kind := range m.world.Tiles {
+		switch kind {
+		case physics.Solid:
+			terrain[i] = mapSolid
+		case physics.OneWay:
+			terrain[i] = mapOneWay
+		}
+	}
+	m.terrain = newTileTexture(columns, rows, terrain)
+
+	m.explored = make([]bool, columns*rows)
+	m.fogPixels = make([]rl.Color, columns*rows)
+	for i := range m.fogPixels {
+		m.fogPixels[i] = mapFog
+	}
+	m.fog = newTileTexture(columns, rows, m.fogPixels)
+	m.fogChanged = false
+}
+
+// newTileTexture makes a texture with one pixel per tile from pixels, row by row
+func newTileTexture(columns, rows int, pixels []rl.Color) rl.Texture2D {
+	image := rl.GenImageColor(columns, rows, rl.Blank)
+	texture := rl.LoadTextureFromImage(image)
+	rl.UnloadImage(image)
+	rl.UpdateTexture(texture, pixels)
+	return texture
+}
+
+// unload frees the map's textures
+func (m *miniMap) unload() {
+	rl.UnloadTexture(m.terrain)
+	rl.UnloadTexture(m.fog)
+	*m = miniMap{}
+}
+
+// reveal clears the fog from every tile in area, a part of the world
+func (m *miniMap) reveal(area rl.Rectangle) {
+	firstCol := max(int(area.X/m.world.TileWidth), 0)
+	firstRow := max(int(area.Y/m.world.TileHeight), 0)
+	lastCol := min(int((area.X+area.Width)/m.world.TileWidth), m.world.Columns-1)
+	lastRow := min(int((area.Y+area.Height)/m.world.TileHeight), m.world.Rows-1)
+	for row := firstRow; row <= lastRow; row++ {
+		for col := firstCol; col <= lastCol; col++ {
+			i := row*m.world.Columns + col
+			if !m.explored[i] {
+				m.explored[i] = true
+				m.fogPixels[i] = rl.Blank
+				m.fogChanged = true
+			}
+		}
+	}
+}
+
+// seen reports whether the tile under point has been explored
+func (m *miniMap) seen(point rl.Vector2) bool {
+	col, row := int(point.X/m.world.TileWidth), int(point.Y/m.world.TileHeight)
+	if col < 0 || row < 0 || col >= m.world.Columns || row >= m.world.Rows {
+		return false
+	}
+	return m.explored[row*m.world.Columns+col]
+}
+
+// DrawMiniMap draws the map in the corner, or over the whole screen when it's toggled
+func DrawMiniMap() {
+	if minimap.fogChanged {
+		rl.UpdateTexture(minimap.fog, minimap.fogPixels)
+		minimap.fogChanged = false
+	}
+	worldSize := rl.Vector2{X: float32(worldWidth), Y: float32(worldHeight)}
+
+	if minimap.fullScreen {
+		// The whole level, as big as fits
+		rl.DrawRectangle(0, 0, screenWidth, screenHeight, rl.Fade(rl.Black, 0.7))
+		scale := min((screenWidth-2*fullMapMargin)/worldSize.X, (screenHeight-2*fullMapMargin)/worldSize.Y)
+		screen := rl.Rectangle{Width: worldSize.X * scale, Height: worldSize.Y * scale}
+		screen.X = (screenWidth - screen.Width) / 2
+		screen.Y = (screenHeight - screen.Height) / 2
+		minimap.draw(rl.Rectangle{Width: worldSize.X, Height: worldSize.Y}, screen, 4)
+		drawCenteredText("Map", int32(screen.Y)-28, 20, rl.RayWhite)
+		drawCenteredText("Press "+actionHint(input.ToggleMap)+" to close", int32(screen.Y+screen.Height)+10, 10, rl.LightGray)
+		return
+	}
+
+	// The part of the level around the player, kept inside the level
+	screen := rl.Rectangle{X: miniMapX, Y: miniMapY, Width: miniMapWidth, Height: miniMapHeight}
+	area := rl.Rectangle{Width: screen.Width / miniMapScale, Height: screen.Height / miniMapScale}
+	player := gameobjects.PlayerInstance.Position
+	area.X = clampFloat(player.X-area.Width/2, 0, max(worldSize.X-area.Width, 0))
+	area.Y = clampFloat(player.Y-area.Height/2, 0, max(worldSize.Y-area.Height, 0))
+	minimap.draw(area, screen, 2.5)
+}
+
+// draw draws area, a part of the world, into screen, a rectangle on screen,
+// with markers of radius marker
+func (m *miniMap) draw(area, screen rl.Rectangle, marker float32) {
+	scale := screen.Width / area.Width
+	toScreen := func(point rl.Vector2) rl.Vector2 {
+		return rl.Vector2{X: screen.X + (point.X-area.X)*scale, Y: screen.Y + (point.Y-area.Y)*scale}
+	}
+	toScreenRect := func(rect rl.Rectangle) rl.Rectangle {
+		corner := toScreen(rl.Vector2{X: rect.X, Y: rect.Y})
+		return rl.Rectangle{X: corner.X, Y: corner.Y, Width: rect.Width * scale, Height: rect.Height * scale}
+	}
+
+	rl.DrawRectangleRec(screen, mapBackground)
+	rl.BeginScissorMode(int32(screen.X), int32(screen.Y), int32(screen.Width), int32(screen.Height))
+
+	// Terrain then fog, only where the area overlaps the level
+	bounds := rl.Rectangle{Width: float32(worldWidth), Height: float32(worldHeight)}
+	if shown := rl.GetCollisionRec(area, bounds); shown.Width > 0 && shown.Height > 0 {
+		source := rl.Rectangle{
+			X:      shown.X / m.world.TileWidth,
+			Y:      shown.Y / m.world.TileHeight,
+			Width:  shown.Width / m.world.TileWidth,
+			Height: shown.Height / m.world.TileHeight,
+		}
+		for _, platform := range m.world.Platforms {
+			color := mapSolid
+			if platform.OneWay {
+				color = mapOneWay
+			}
+			rl.DrawRectangleRec(toScreenRect(platform.Rect), color)
+		}
+		rl.DrawTexturePro(m.terrain, source, toScreenRect(shown), rl.Vector2{}, 0, rl.White)
+		rl.DrawTexturePro(m.fog, source, toScreenRect(shown), rl.Vector2{}, 0, rl.White)
+	}
+
+	// Only what's in explored parts of the level is marked
+	for _, exit := range m.exits {
+		if m.seen(rl.Vector2{X: exit.X + exit.Width/2, Y: exit.Y + exit.Height/2}) {
+			color := rl.DarkGreen
+			if waves.done && len(zombies) == 0 {
+				color = rl.Lime // Lit up once it's time to leave
+			}
+			rect := toScreenRect(exit)
+			rect.Width, rect.Height = max(rect.Width, marker*2), max(rect.Height, marker*2)
+			rl.DrawRectangleLinesEx(rect, 1, color)
+		}
+	}
+	for _, item := range worldItems.All() {
+		if center := item.Center(); m.seen(center) {
+			position := toScreen(center)
+			rl.DrawRectangleV(rl.Vector2{X: position.X - marker/2, Y: position.Y - marker/2}, rl.Vector2{X: marker, Y: marker}, rl.Gold)
+		}
+	}
+	for _, zombie := range zombies {
+		if color, ok := zombieMarkers[zombie.State]; ok && zombie.IsAlive && m.seen(zombie.Position) {
+			rl.DrawCircleV(toScreen(zombie.Position), marker*0.8, color)
+		}
+	}
+	rl.DrawRectangleLinesEx(toScreenRect(cam.View()), 1, rl.Fade(rl.RayWhite, 0.5))
+	rl.DrawCircleV(toScreen(gameobjects.PlayerInstance.Position), marker, rl.SkyBlue)
+
+	rl.EndScissorMode()
+	rl.DrawRectangleLinesEx(screen, 1, rl.DarkGray)
+}
@@ -0,0 +1,290 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"platformer-game/gameobjects"
+	"platformer-game/resources"
+	"platformer-game/savegame"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const noticeDuration = 2.0 // Seconds a notice stays on screen
+
+// saveSlotKeys are the keys for the numbered save slots, from slot 1
+var saveSlotKeys = []int32{rl.KeyOne, rl.KeyTwo, rl.KeyThree}
+
+var (
+	notice      string  // Short message at the bottom of the screen, like "Game saved"
+	noticeTimer float32 // Seconds left of the notice
+)
+
+// showNotice puts a short message at the bottom of the screen for a moment
+func showNotice(text string) {
+	notice = text
+	noticeTimer = noticeDuration
+}
+
+// drawNotice draws the notice, fading out over its last second. It runs down
+// in real time so notices also go away in scenes that don't update.
+func drawNotice() {
+	if noticeTimer <= 0 {
+		return
+	}
+	drawCenteredText(notice, screenHeight-40, 20, rl.Fade(rl.RayWhite, min(noticeTimer, 1)))
+	noticeTimer -= rl.GetFrameTime()
+}
+
+// SaveGame writes the current run to slot
+func SaveGame(slot string) error {
+	if currentLevel == nil {
+		return errors.New("no game is running")
+	}
+	player := &gameobjects.PlayerInstance
+	f := savegame.File{
+		LevelID:   currentLevel.ID,
+		LevelPath: currentLevel.Path,
+		Player: savegame.Player{
+			Position:     player.Position,
+			Speed:        player.Speed,
+			FacingRight:  player.FacingRight,
+			Health:       player.Health,
+			MaxHealth:    player.MaxHealth,
+			Coins:        player.Coins,
+			Score:        player.Score,
+			SelectedSlot: player.Inventory.SelectedSlot,
+		},
+		Waves: savegame.Waves{
+			Wave:    waves.wave,
+			Queue:   waves.queue,
+			Timer:   waves.timer,
+			InBreak: waves.inBreak,
+			Done:    waves.done,
+		},
+	}
+
+	for _, item := range player.Inventory.Slots {
+		f.Player.Inventory = append(f.Player.Inventory, savedStack(item))
+	}
+	f.Player.Held = savedStack(player.Inventory.Dragged())
+	for name, gun := range player.Guns {
+		f.Player.Guns = append(f.Player.Guns, savegame.Gun{Name: name, Ammo: gun.Ammo, Reserve: gun.Reserve})
+	}
+	for _, buff := range player.Buffs {
+		f.Player.Buffs = append(f.Player.Buffs, savegame.Buff{
+			Name:      buff.Name,
+			Kind:      int(buff.Kind),
+			Amount:    buff.Amount,
+			Duration:  buff.Duration,
+			Remaining: buff.Remaining,
+		})
+	}
+
+	// Dying zombies are as good as dead
+	for _, zombie := range zombies {
+		if !zombie.IsAlive {
+			continue
+		}
+		f.Zombies = append(f.Zombies, savegame.Zombie{
+			Type:         zombie.Type,
+			Position:     zombie.Position,
+			FacingRight:  zombie.FacingRight,
+			Health:       zombie.Health,
+			AttackDamage: zombie.Archetype.AttackDamage,
+			RoamSpeed:    zombie.Archetype.RoamSpeed,
+			ChaseSpeed:   zombie.Archetype.ChaseSpeed,
+			FollowRange:  zombie.Archetype.FollowRange,
+			Spawned:      waves.isSpawned(zombie),
+		})
+	}
+
+	for _, item := range worldItems.All() {
+		f.Items = append(f.Items, savegame.WorldItem{
+			ID:       item.ID,
+			Name:     item.Name,
+			Type:     int(item.Type),
+			Texture:  item.TexturePath,
+			Count:    item.Count,
+			Position: item.Position,
+			Velocity: item.Velocity,
+		})
+	}
+
+	return savegame.Write(slot, &f)
+}
+
+// LoadGame ends the current run, if any, and carries on with the one saved in
+// slot. A save that can't be restored leaves the player on the title screen.
+func LoadGame(slot string) error {
+	f, err := savegame.Read(slot)
+	if err != nil {
+		return err
+	}
+	if err := checkSave(f); err != nil {
+		return err
+	}
+	return startRun(NewLoadedScene(f))
+}
+
+// checkSave catches what would stop f from being restored before the
+// current run is thrown away for it
+func checkSave(f *savegame.File) error {
+	if _, err := os.Stat(f.LevelPath); err != nil {
+		return fmt.Errorf("level: %w", err)
+	}
+	for _, zombie := range f.Zombies {
+		if _, err := gameobjects.ZombieArchetypeFor(zombie.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreGame starts the saved level from scratch, then puts everything back
+// where it was when f was saved
+func restoreGame(f *savegame.File) error {
+	if err := InitGame(f.LevelPath); err != nil {
+		return err
+	}
+	if currentLevel.ID != f.LevelID {
+		log.Printf("Save was made on level %q but %s is now %q", f.LevelID, f.LevelPath, currentLevel.ID)
+	}
+
+	player := &gameobjects.PlayerInstance
+	player.Position = f.Player.Position
+	player.Speed = f.Player.Speed
+	player.FacingRight = f.Player.FacingRight
+	player.Health = f.Player.Health
+	player.MaxHealth = f.Player.MaxHealth
+	player.Coins = f.Player.Coins
+	player.Score = f.Player.Score
+	for i, stack := range f.Player.Inventory {
+		if i >= len(player.Inventory.Slots) || stack.Count <= 0 {
+			continue
+		}
+		player.Inventory.Slots[i] = gameobjects.Item{
+			ID:          stack.ID,
+			Type:        gameobjects.ItemType(stack.Type),
+			Name:        stack.Name,
+			Image:       resources.Texture(stack.Texture),
+			TexturePath: stack.Texture,
+			Count:       stack.Count,
+		}
+	}
+	player.Inventory.SelectedSlot = clamp(f.Player.SelectedSlot, 0, len(player.Inventory.Slots)-1)
+	for _, saved := range f.Player.Guns {
+		if gun := player.GunFor(saved.Name); gun != nil {
+			gun.Ammo, gun.Reserve = saved.Ammo, saved.Reserve
+		}
+	}
+	for _, buff := range f.Player.Buffs {
+		player.Buffs = append(player.Buffs, gameobjects.ActiveBuff{
+			Buff: gameobjects.Buff{
+				Name:     buff.Name,
+				Kind:     gameobjects.BuffKind(buff.Kind),
+				Amount:   buff.Amount,
+				Duration: buff.Duration,
+			},
+			Remaining: buff.Remaining,
+		})
+	}
+	player.UpdateHeldItem()
+
+	// The level's own zombies and items are replaced by the saved ones
+	for _, zombie := range zombies {
+		zombie.Unload()
+	}
+	zombies = nil
+	waves.spawned = nil
+	for _, saved := range f.Zombies {
+		zombie, err := gameobjects.InitZombie(saved.Position.X, saved.Position.Y, saved.Type)
+		if err != nil {
+			return err
+		}
+		zombie.FacingRight = saved.FacingRight
+		zombie.Health = saved.Health
+		zombie.Archetype.AttackDamage = saved.AttackDamage
+		zombie.Archetype.RoamSpeed = saved.RoamSpeed
+		zombie.Archetype.ChaseSpeed = saved.ChaseSpeed
+		zombie.Archetype.FollowRange = saved.FollowRange
+		zombies = append(zombies, &zombie)
+		if saved.Spawned {
+			waves.spawned = append(waves.spawned, &zombie)
+		}
+	}
+
+	worldItems.Clear()
+	for _, saved := range f.Items {
+		item := gameobjects.NewWorldItem(saved.Position.X, saved.Position.Y, saved.ID, gameobjects.ItemType(saved.Type), saved.Name, saved.Texture, saved.Count)
+		item.Velocity = saved.Velocity
+		worldItems.Spawn(item)
+	}
+
+	waves.wave = f.Waves.Wave
+	waves.queue = f.Waves.Queue
+	waves.timer = f.Waves.Timer
+	waves.inBreak = f.Waves.InBreak
+	waves.done = f.Waves.Done
+
+	// A stack that was held by the mouse goes back in the inventory, what
+	// doesn't fit is dropped at the player's feet
+	if held := f.Player.Held; held.Count > 0 {
+		item := gameobjects.Item{
+			ID:          held.ID,
+			Type:        gameobjects.ItemType(held.Type),
+			Name:        held.Name,
+			TexturePath: held.Texture,
+			Count:       held.Count,
+		}
+		if left := player.Inventory.AddItem(item); left > 0 {
+			item.Image = resources.Texture(held.Texture)
+			item.Count = left
+			dropItem(item)
+		}
+	}
+
+	cam.SnapTo(player.Position)
+	return nil
+}
+
+// savedStack is an inventory stack as it's saved
+func savedStack(item gameobjects.Item) savegame.Stack {
+	return savegame.Stack{
+		ID:      item.ID,
+		Name:    item.Name,
+		Type:    int(item.Type),
+		Texture: item.TexturePath,
+		Count:   item.Count,
+	}
+}
+
+// saveSlot and loadSlot save and load a slot, with a notice either way
+func saveSlot(slot string) {
+	if err := SaveGame(slot); err != nil {
+		log.Printf("Saving to %s failed: %v", slotLabel(slot), err)
+		showNotice("Saving failed")
+		return
+	}
+	showNotice("Saved to " + slotLabel(slot))
+}
+
+func loadSlot(slot string) {
+	if err := LoadGame(slot); err != nil {
+		log.Printf("Loading %s failed: %v", slotLabel(slot), err)
+		showNotice(fmt.Sprintf("Couldn't load %s", slotLabel(slot)))
+		return
+	}
+	showNotice("Loaded " + slotLabel(slot))
+}
+
+// slotLabel names a save slot for notices
+func slotLabel(slot string) string {
+	if slot == savegame.QuickSlot {
+		return "quicksave"
+	}
+	return "slot " + slot
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"platformer-game/input"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Scene is one screen of the game, like the title menu or a running level.
+// Scenes live on a stack: only the top one receives input and updates, but
+// every scene is drawn bottom to top so overlays like pause show the world
+// underneath.
+type Scene interface {
+	Enter()            // Called when the scene is pushed onto the stack
+	Exit()             // Called when the scene is popped off the stack
+	HandleInput()      // Called once per rendered frame while on top
+	Update(dt float32) // Called once per fixed simulation step while on top
+	Draw()             // Called every frame, after the scenes below it
+}
+
+var (
+	scenes        []Scene // Scene stack, the last entry is on top
+	quitRequested bool
+)
+
+// PushScene puts a scene on top of the stack and enters it
+func PushScene(scene Scene) {
+	input.Flush() // Presses meant for the scene below don't carry over
+	scenes = append(scenes, scene)
+	scene.Enter()
+}
+
+// PopScene exits and removes the top scene
+func PopScene() {
+	if len(scenes) == 0 {
+		return
+	}
+	top := scenes[len(scenes)-1]
+	scenes = scenes[:len(scenes)-1]
+	top.Exit()
+	input.Flush()
+}
+
+// ChangeScene clears the whole stack and starts over with the given scene
+func ChangeScene(scene Scene) {
+	for len(scenes) > 0 {
+		PopScene()
+	}
+	PushScene(scene)
+}
+
+// CurrentScene returns the scene on top of the stack, or nil if it's empty
+func CurrentScene() Scene {
+	if len(scenes) == 0 {
+		return nil
+	}
+	return scenes[len(scenes)-1]
+}
+
+// RequestQuit asks the main loop to close the game after this frame
+func RequestQuit() {
+	quitRequested = true
+}
+
+// ShouldQuit reports whether the game asked to be closed
+func ShouldQuit() bool {
+	return quitRequested || len(scenes) == 0
+}
+
+// Shutdown exits every scene so they can free what they loaded
+func Shutdown() {
+	for len(scenes) > 0 {
+		PopScene()
+	}
+}
+
+// Draw renders every scene on the stack, bottom to top, then any notice on top
+func Draw() {
+	rl.BeginDrawing()
+	rl.ClearBackground(rl.RayWhite)
+	for _, scene := range scenes {
+		scene.Draw()
+	}
+	drawNotice()
+	rl.EndDrawing()
+}
@@ -0,0 +1,363 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"platformer-game/gameobjects"
+	"platformer-game/input"
+	"platformer-game/savegame"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+/***********************************TITLE*********************************************** */
+
+// TitleScene is the main menu shown on launch and after quitting a run
+type TitleScene struct {
+	latest *savegame.Slot // Most recent save, nil if there isn't one
+}
+
+func NewTitleScene() *TitleScene {
+	return &TitleScene{}
+}
+
+func (s *TitleScene) Enter() {
+	if slots, err := savegame.Slots(); err == nil && len(slots) > 0 {
+		s.latest = &slots[0]
+	}
+}
+func (s *TitleScene) Exit() {}
+
+func (s *TitleScene) HandleInput() {
+	switch {
+	case menuConfirm():
+		startLevel(DefaultLevel)
+	case (rl.IsKeyPressed(rl.KeyC) || input.PadPressed(rl.GamepadButtonRightFaceLeft)) && s.latest != nil:
+		loadSlot(s.latest.Name) // Carry on with the latest save
+	case rl.IsKeyPressed(rl.KeyK) || input.PadPressed(rl.GamepadButtonRightFaceUp):
+		PushScene(NewControlsScene())
+	case rl.IsKeyPressed(rl.KeyEscape):
+		RequestQuit()
+	}
+}
+
+func (s *TitleScene) Update(dt float32) {}
+
+func (s *TitleScene) Draw() {
+	rl.ClearBackground(rl.Black)
+	drawCenteredText("Zombie Platformer", screenHeight/2-60, 40, rl.Red)
+	start := "Press Enter to start"
+	if input.GamepadConnected() {
+		start = "Press Enter or A to start"
+	}
+	drawCenteredText(start, screenHeight/2+10, 20, rl.RayWhite)
+	drawCenteredText("Press Esc to quit", screenHeight/2+40, 20, rl.Gray)
+	if s.latest != nil {
+		text := fmt.Sprintf("Press C to continue (%s, %s)", slotLabel(s.latest.Name), s.latest.SavedAt.Format("Jan 2 15:04"))
+		drawCenteredText(text, screenHeight/2+70, 20, rl.Gray)
+	}
+	drawCenteredText("Press K to change controls", screenHeight-30, 10, rl.Gray)
+}
+
+/***********************************PLAYING*********************************************** */
+
+// PlayingScene owns a single run of the game, from spawning in to dying or winning
+type PlayingScene struct {
+	levelPath string
+	save      *savegame.File // Run to carry on with instead of starting the level fresh
+	err       error          // Why the level or save couldn't be loaded, see startRun
+}
+
+func NewPlayingScene(levelPath string) *PlayingScene {
+	return &PlayingScene{levelPath: levelPath}
+}
+
+// NewLoadedScene carries on with a saved run
+func NewLoadedScene(save *savegame.File) *PlayingScene {
+	return &PlayingScene{levelPath: save.LevelPath, save: save}
+}
+
+func (s *PlayingScene) Enter() {
+	if s.save != nil {
+		s.err = restoreGame(s.save)
+		return
+	}
+	s.err = InitGame(s.levelPath)
+}
+
+// startRun changes to scene, going back to the title screen if its level or
+// save couldn't be loaded
+func startRun(scene *PlayingScene) error {
+	ChangeScene(scene)
+	if scene.err != nil {
+		ChangeScene(NewTitleScene())
+	}
+	return scene.err
+}
+
+// startLevel starts a fresh run on the level at path, with a notice if it fails
+func startLevel(path string) {
+	if err := startRun(NewPlayingScene(path)); err != nil {
+		log.Printf("Starting %s failed: %v", path, err)
+		showNotice("Couldn't start the level")
+	}
+}
+
+func (s *PlayingScene) Exit() {
+	UnloadGame()
+}
+
+func (s *PlayingScene) HandleInput() {
+	switch {
+	case pausePressed():
+		PushScene(NewPausedScene())
+		return
+	case input.Pressed(input.QuickSave):
+		saveSlot(savegame.QuickSlot)
+	case input.Pressed(input.QuickLoad):
+		loadSlot(savegame.QuickSlot)
+		return
+	}
+	handleGameInput()
+}
+
+func (s *PlayingScene) Update(dt float32) {
+	UpdateGame(dt)
+
+	switch {
+	case gameobjects.PlayerInstance.IsGameOver():
+		PushScene(NewGameOverScene())
+	case levelCleared():
+		PushScene(NewVictoryScene())
+	}
+}
+
+func (s *PlayingScene) Draw() {
+	DrawGame()
+}
+
+/***********************************PAUSED*********************************************** */
+
+// PausedScene freezes the run underneath it until the player resumes
+type PausedScene struct{}
+
+func NewPausedScene() *PausedScene {
+	return &PausedScene{}
+}
+
+func (s *PausedScene) Enter() {}
+func (s *PausedScene) Exit()  {}
+
+func (s *PausedScene) HandleInput() {
+	switch {
+	case pausePressed(), input.PadPressed(rl.GamepadButtonRightFaceRight):
+		PopScene()
+		return
+	case rl.IsKeyPressed(rl.KeyR):
+		startLevel(currentLevel.Path)
+		return
+	case rl.IsKeyPressed(rl.KeyQ), input.PadPressed(rl.GamepadButtonMiddleLeft):
+		ChangeScene(NewTitleScene())
+		return
+	case rl.IsKeyPressed(rl.KeyK), input.PadPressed(rl.GamepadButtonRightFaceUp):
+		PushScene(NewControlsScene())
+		return
+	}
+
+	// Number keys save to a slot, with Shift they load it
+	for i, key := range saveSlotKeys {
+		if !rl.IsKeyPressed(key) {
+			continue
+		}
+		slot := fmt.Sprint(i + 1)
+		if rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift) {
+			loadSlot(slot)
+		} else {
+			saveSlot(slot)
+		}
+	}
+}
+
+func (s *PausedScene) Update(dt float32) {}
+
+func (s *PausedScene) Draw() {
+	drawOverlay("Paused", rl.RayWhite, "Esc to resume, R to restart, Q for title")
+	drawCenteredText("1-3 to save to a slot, Shift+1-3 to load one", screenHeight/2+50, 10, rl.LightGray)
+	drawCenteredText("K to change controls", screenHeight/2+65, 10, rl.LightGray)
+}
+
+/***********************************GAME OVER*********************************************** */
+
+// GameOverScene is shown over the frozen world once the player dies
+type GameOverScene struct{}
+
+func NewGameOverScene() *GameOverScene {
+	return &GameOverScene{}
+}
+
+func (s *GameOverScene) Enter() {}
+func (s *GameOverScene) Exit()  {}
+
+func (s *GameOverScene) HandleInput() {
+	switch {
+	case menuConfirm(), rl.IsKeyPressed(rl.KeyR):
+		startLevel(currentLevel.Path)
+	case menuBack(), rl.IsKeyPressed(rl.KeyQ):
+		ChangeScene(NewTitleScene())
+	}
+}
+
+func (s *GameOverScene) Update(dt float32) {}
+
+func (s *GameOverScene) Draw() {
+	drawOverlay("Game Over", rl.Red, "Enter to try again, Esc for title")
+}
+
+/***********************************VICTORY*********************************************** */
+
+// VictoryScene is shown over the world once every wave is cleared and every zombie is dead
+type VictoryScene struct{}
+
+func NewVictoryScene() *VictoryScene {
+	return &VictoryScene{}
+}
+
+func (s *VictoryScene) Enter() {}
+func (s *VictoryScene) Exit()  {}
+
+func (s *VictoryScene) HandleInput() {
+	switch {
+	case menuConfirm(), rl.IsKeyPressed(rl.KeyR):
+		startLevel(currentLevel.Path)
+	case menuBack(), rl.IsKeyPressed(rl.KeyQ):
+		ChangeScene(NewTitleScene())
+	}
+}
+
+func (s *VictoryScene) Update(dt float32) {}
+
+func (s *VictoryScene) Draw() {
+	drawOverlay("You Survived!", rl.Gold, "Enter to play again, Esc for title")
+}
+
+/***********************************CONTROLS*********************************************** */
+
+// ControlsScene lists every action with its bindings and lets the player
+// rebind them, saving to the user's config file on the way out
+type ControlsScene struct {
+	selected int  // Highlighted action
+	waiting  bool // Waiting for the input to bind to the selected action
+	changed  bool // Bindings need saving
+}
+
+func NewControlsScene() *ControlsScene {
+	return &ControlsScene{}
+}
+
+func (s *ControlsScene) Enter() {}
+
+func (s *ControlsScene) Exit() {
+	if !s.changed {
+		return
+	}
+	if err := input.SaveUserConfig(); err != nil {
+		log.Printf("Saving key bindings failed: %v", err)
+		showNotice("Couldn't save controls")
+	}
+}
+
+func (s *ControlsScene) HandleInput() {
+	action := input.Actions[s.selected]
+	if s.waiting {
+		// Esc cancels rather than being bound, so the menu can always be left
+		if rl.IsKeyPressed(rl.KeyEscape) {
+			s.waiting = false
+		} else if b, ok := input.Capture(); ok {
+			input.Bind(action, b)
+			s.waiting = false
+			s.changed = true
+		}
+		return
+	}
+
+	switch {
+	case menuBack():
+		PopScene()
+	case rl.IsKeyPressed(rl.KeyUp), input.PadPressed(rl.GamepadButtonLeftFaceUp):
+		s.selected = (s.selected - 1 + len(input.Actions)) % len(input.Actions)
+	case rl.IsKeyPressed(rl.KeyDown), input.PadPressed(rl.GamepadButtonLeftFaceDown):
+		s.selected = (s.selected + 1) % len(input.Actions)
+	case menuConfirm():
+		s.waiting = true
+	case rl.IsKeyPressed(rl.KeyBackspace), input.PadPressed(rl.GamepadButtonRightFaceLeft):
+		input.Reset(action)
+		s.changed = true
+	}
+}
+
+func (s *ControlsScene) Update(dt float32) {}
+
+func (s *ControlsScene) Draw() {
+	rl.DrawRectangle(0, 0, screenWidth, screenHeight, rl.Fade(rl.Black, 0.85))
+	drawCenteredText("Controls", 20, 30, rl.RayWhite)
+
+	const rowHeight = 20
+	y := int32(65)
+	for i, action := range input.Actions {
+		color := rl.LightGray
+		if i == s.selected {
+			color = rl.Yellow
+			rl.DrawRectangle(150, y-3, screenWidth-300, rowHeight, rl.Fade(rl.Yellow, 0.15))
+		}
+		var bound []string
+		for _, b := range input.Bound(action) {
+			bound = append(bound, b.String())
+		}
+		text := strings.Join(bound, " / ")
+		if text == "" {
+			text = "(unbound)"
+		}
+		if i == s.selected && s.waiting {
+			text = "Press a key or button..."
+		}
+		rl.DrawText(action.Label(), 160, y, 10, color)
+		rl.DrawText(text, 360, y, 10, color)
+		y += rowHeight
+	}
+
+	hint := "Up/Down to pick, Enter to rebind, Backspace to reset, Esc to go back"
+	if s.waiting {
+		hint = "Esc to cancel"
+	}
+	drawCenteredText(hint, screenHeight-30, 10, rl.RayWhite)
+}
+
+/***********************************HELPERS*********************************************** */
+
+// menuConfirm, menuBack and pausePressed read menu buttons from the keyboard or
+// the gamepad. Menus don't go through bindings, so they can't be rebound away.
+func menuConfirm() bool {
+	return rl.IsKeyPressed(rl.KeyEnter) || input.PadPressed(rl.GamepadButtonRightFaceDown)
+}
+
+func menuBack() bool {
+	return rl.IsKeyPressed(rl.KeyEscape) || input.PadPressed(rl.GamepadButtonRightFaceRight)
+}
+
+func pausePressed() bool {
+	return rl.IsKeyPressed(rl.KeyEscape) || input.PadPressed(rl.GamepadButtonMiddleRight)
+}
+
+// drawOverlay dims whatever was drawn before it and shows a title with a hint line
+func drawOverlay(title string, color rl.Color, hint string) {
+	rl.DrawRectangle(0, 0, screenWidth, screenHeight, rl.Fade(rl.Black, 0.6))
+	drawCenteredText(title, screenHeight/2-40, 40, color)
+	drawCenteredText(hint, screenHeight/2+20, 20, rl.RayWhite)
+}
+
+func drawCenteredText(text string, y int32, fontSize int32, color rl.Color) {
+	width := rl.MeasureText(text, fontSize)
+	rl.DrawText(text, screenWidth/2-width/2, y, fontSize, color)
+}
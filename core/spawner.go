@@ -0,0 +1,283 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+
+	"platformer-game/gameobjects"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// DefaultWaves is the wave file used by levels that don't name their own
+const DefaultWaves = "assets/waves/default.json"
+
+// WaveConfig is a wave file: the waves to run and how they get harder
+type WaveConfig struct {
+	BreakTime     float32 `json:"breakTime"`     // Seconds before the first wave and between waves
+	SpawnInterval float32 `json:"spawnInterval"` // Seconds between spawns within a wave
+	MaxAlive      int     `json:"maxAlive"`      // Spawning pauses while this many zombies are alive
+	SpawnMargin   float32 `json:"spawnMargin"`   // How far past the edge of the screen zombies appear
+	HealthGrowth  float32 `json:"healthGrowth"`  // Extra zombie health per wave, 0.1 is +10%
+	DamageGrowth  float32 `json:"damageGrowth"`  // Extra zombie damage per wave
+	SpeedGrowth   float32 `json:"speedGrowth"`   // Extra zombie speed per wave
+	Endless       bool    `json:"endless"`       // Keep repeating the last wave instead of ending
+	CountGrowth   float32 `json:"countGrowth"`   // Extra zombies per repeat of the last wave
+	Waves         []Wave  `json:"waves"`
+}
+
+// Wave is the zombies spawned in one wave
+type Wave struct {
+	Groups        []WaveGroup `json:"zombies"`
+	SpawnInterval float32     `json:"spawnInterval"` // Overrides the config's when set
+}
+
+// WaveGroup is a number of zombies of one type
+type WaveGroup struct {
+	Type  int `json:"type"` // Zombie type, like gameobjects.ZombieGirl
+	Count int `json:"count"`
+}
+
+// LoadWaves reads a wave file
+func LoadWaves(path string) (WaveConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return WaveConfig{}, err
+	}
+	var config WaveConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return WaveConfig{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i, wave := range config.Waves {
+		for _, group := range wave.Groups {
+			if _, err := gameobjects.ZombieArchetypeFor(group.Type); err != nil {
+				return WaveConfig{}, fmt.Errorf("%s: wave %d: %w", path, i+1, err)
+			}
+		}
+	}
+	if config.MaxAlive <= 0 {
+		config.MaxAlive = math.MaxInt
+	}
+	return config, nil
+}
+
+// spawner runs the waves of a level, spawning zombies out of sight of the camera
+type spawner struct {
+	config  WaveConfig
+	wave    int                   // Number of the current wave, or the last one during a break
+	queue   []int                 // Zombie types still to spawn this wave
+	spawned []*gameobjects.Zombie // Zombies spawned this wave, level-placed ones don't hold it up
+	timer   float32               // Seconds until the break ends, or until the next spawn
+	inBreak bool
+	done    bool // Every wave has been cleared
+}
+
+var waves spawner
+
+func newSpawner(config WaveConfig) spawner {
+	return spawner{
+		config:  config,
+		timer:   config.BreakTime,
+		inBreak: true,
+		done:    len(config.Waves) == 0,
+	}
+}
+
+// Update counts down breaks and spawns the current wave's zombies
+func (s *spawner) Update(dt float32) {
+	if s.done {
+		return
+	}
+	s.timer -= dt
+
+	if s.inBreak {
+		if s.timer <= 0 {
+			s.startWave(s.wave + 1)
+		}
+		return
+	}
+
+	if len(s.queue) > 0 {
+		if s.timer <= 0 && aliveZombies() < s.config.MaxAlive {
+			zombie, err := spawnZombie(s.queue[0], s.difficulty(), s.config.SpawnMargin)
+			switch {
+			case err != nil:
+				// Retrying would hold the wave up forever, so the zombie is left out
+				log.Printf("Wave %d: couldn't spawn a zombie of type %d: %v", s.wave, s.queue[0], err)
+				s.queue = s.queue[1:]
+			case zombie != nil:
+				s.queue = s.queue[1:]
+				s.spawned = append(s.spawned, zombie)
+			}
+			s.timer = s.spawnInterval()
+		}
+		return
+	}
+
+	// Wave cleared once everything it spawned is dead
+	if s.aliveSpawned() == 0 {
+		if s.wave >= len(s.config.Waves) && !s.config.Endless {
+			s.done = true
+			return
+		}
+		s.inBreak = true
+		s.timer = s.config.BreakTime
+	}
+}
+
+// startWave queues up the zombies of wave n, counting from 1. Past the last
+// wave of an endless config the last wave repeats with more zombies.
+func (s *spawner) startWave(n int) {
+	s.wave = n
+	s.inBreak = false
+	s.timer = 0
+
+	last := len(s.config.Waves)
+	wave := s.config.Waves[min(n, last)-1]
+	countScale := float32(1)
+	if n > last {
+		countScale += s.config.CountGrowth * float32(n-last)
+	}
+
+	s.queue = s.queue[:0]
+	s.spawned = nil
+	for _, group := range wave.Groups {
+		count := int(math.Round(float64(float32(group.Count) * countScale)))
+		for i := 0; i < count; i++ {
+			s.queue = append(s.queue, group.Type)
+		}
+	}
+	rand.Shuffle(len(s.queue), func(i, j int) { s.queue[i], s.queue[j] = s.queue[j], s.queue[i] })
+}
+
+func (s *spawner) spawnInterval() float32 {
+	wave := s.config.Waves[min(s.wave, len(s.config.Waves))-1]
+	if wave.SpawnInterval > 0 {
+		return wave.SpawnInterval
+	}
+	return s.config.SpawnInterval
+}
+
+// difficulty scales a zombie's stats over its archetype's
+type difficulty struct {
+	health, damage, speed float32
+}
+
+// difficulty returns how much tougher this wave is, the first wave is the baseline
+func (s *spawner) difficulty() difficulty {
+	step := float32(s.wave - 1)
+	return difficulty{
+		health: 1 + s.config.HealthGrowth*step,
+		damage: 1 + s.config.DamageGrowth*step,
+		speed:  1 + s.config.SpeedGrowth*step,
+	}
+}
+
+// remaining returns how many zombies of the current wave are still to come or alive
+func (s *spawner) remaining() int {
+	return len(s.queue) + s.aliveSpawned()
+}
+
+func (s *spawner) aliveSpawned() int {
+	count := 0
+	for _, zombie := range s.spawned {
+		if zombie.IsAlive {
+			count++
+		}
+	}
+	return count
+}
+
+// isSpawned reports whether zombie belongs to the current wave
+func (s *spawner) isSpawned(zombie *gameobjects.Zombie) bool {
+	return slices.Contains(s.spawned, zombie)
+}
+
+// aliveZombies counts zombies that aren't dead or dying
+func aliveZombies() int {
+	count := 0
+	for _, zombie := range zombies {
+		if zombie.IsAlive {
+			count++
+		}
+	}
+	return count
+}
+
+// spawnZombie drops a zombie onto the ground just off one side of the
+// screen, facing the player. It returns nil when there's no room on either
+// side, the spawn is then retried later, and an error when the zombie can't
+// be made at all, like when its animations fail to load.
+func spawnZombie(zombieType int, d difficulty, margin float32) (*gameobjects.Zombie, error) {
+	zombie, err := gameobjects.InitZombie(0, 0, zombieType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the wave tougher, and let it find the player from anywhere
+	zombie.Health = int(float32(zombie.Health) * d.health)
+	zombie.Archetype.AttackDamage *= float64(d.damage)
+	zombie.Archetype.RoamSpeed *= d.speed
+	zombie.Archetype.ChaseSpeed *= d.speed
+	zombie.Archetype.FollowRange = float32(max(worldWidth, worldHeight))
+
+	view := cameraView()
+	sides := []float32{
+		view.X - margin - zombie.Width/2,
+		view.X + view.Width + margin + zombie.Width/2,
+	}
+	if rand.Intn(2) == 0 {
+		sides[0], sides[1] = sides[1], sides[0]
+	}
+
+	world := currentLevel.Collision
+	for _, x := range sides {
+		if x < zombie.Width/2 || x > world.Width-zombie.Width/2 {
+			continue // Past the edge of the world
+		}
+		// Start at the top of the map and fall to the first thing to stand on
+		zombie.Position = rl.Vector2{X: x, Y: zombie.Height / 2}
+		box := zombie.Hitbox()
+		if world.Overlaps(box) {
+			continue
+		}
+		box, contacts := world.Move(box, rl.Vector2{Y: world.Height}, false)
+		if !contacts.OnGround {
+			continue
+		}
+		zombie.Position = rl.Vector2{X: box.X + box.Width/2, Y: box.Y + box.Height/2}
+		zombie.FacingRight = zombie.Position.X < gameobjects.PlayerInstance.Position.X
+		if !zombie.FacingRight {
+			zombie.Speed.X = -zombie.Speed.X
+		}
+		zombies = append(zombies, &zombie)
+		return &zombie, nil
+	}
+
+	zombie.Unload()
+	return nil, nil
+}
+
+// waveText describes the wave under way, or the countdown during breaks,
+// empty when the level has no waves
+func waveText() string {
+	switch {
+	case len(waves.config.Waves) == 0:
+		return ""
+	case waves.done && len(currentLevel.Exits) > 0:
+		return "All waves cleared - head for the exit"
+	case waves.done:
+		return "All waves cleared"
+	case waves.inBreak:
+		return fmt.Sprintf("Wave %d in %d", waves.wave+1, int(math.Ceil(float64(waves.timer))))
+	case waves.config.Endless:
+		return fmt.Sprintf("Wave %d - %d left", waves.wave, waves.remaining())
+	default:
+		return fmt.Sprintf("Wave %d/%d - %d left", waves.wave, len(waves.config.Waves), waves.remaining())
+	}
+}
@@ -1,37 +1,139 @@
 package gameobjects
 
 import (
+	"math"
+
+	"platformer-game/physics"
+
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+const (
+	maxWallHits     = 4   // Walls a bullet can run into in one update, so ricochets can't loop forever
+	penetrationStep = 2.0 // Pixels between the checks for the far side of a wall
+)
+
 type Bullet struct {
-	Position rl.Vector2
-	Speed    float32
-	Direction rl.Vector2 // Vector indicating direction
-	IsActive bool        // Track if the bullet is active
+	Position    rl.Vector2
+	Speed       float32
+	Direction   rl.Vector2     // Vector indicating direction
+	IsActive    bool           // Track if the bullet is active
+	Damage      int            // Health taken from each zombie hit
+	Pierce      int            // Zombies the bullet can still pass through
+	Def         *ProjectileDef // How the bullet looks and what it does to walls
+	Penetration float32        // Pixels of wall the bullet can still pass through
+	Ricochets   int            // Times the bullet can still glance off walls
+	hits        []*Zombie      // Zombies already hit, so a piercing bullet hits each only once
 }
 
-// Initialize a new bullet based on the player’s position and facing direction
-func NewBullet(x, y, speed float32, facingRight bool) *Bullet {
-	direction := rl.NewVector2(1, 0)
-	if !facingRight {
-		direction.X = -1
-	}
+// Initialize a new bullet at position, travelling along the unit vector direction
+func NewBullet(position, direction rl.Vector2, speed float32, damage, pierce int, def *ProjectileDef) *Bullet {
 	return &Bullet{
-		Position:  rl.Vector2{X: x, Y: y},
-		Speed:     speed,
-		Direction: direction,
-		IsActive:  true,
+		Position:    position,
+		Speed:       speed,
+		Direction:   direction,
+		IsActive:    true,
+		Damage:      damage,
+		Pierce:      pierce,
+		Def:         def,
+		Penetration: def.Penetration,
+		Ricochets:   def.Ricochets,
+	}
+}
+
+// Update moves the bullet by its speed (pixels per second) along its
+// direction, stopping it at, passing it through or bouncing it off the walls
+// in its way
+func (b *Bullet) Update(dt float32, world *physics.World) {
+	b.travel(b.Speed*dt, world)
+}
+
+// travel moves the bullet distance pixels along its direction through world
+func (b *Bullet) travel(distance float32, world *physics.World) {
+	remaining := distance
+	for i := 0; i < maxWallHits && b.IsActive && remaining > 0; i++ {
+		end := rl.Vector2Add(b.Position, rl.Vector2Scale(b.Direction, remaining))
+		hit, ok := world.Raycast(b.Position, end)
+		if !ok {
+			b.Position = end
+			return
+		}
+		remaining -= remaining * hit.T
+		b.Position = hit.Point
+		b.hitWall(world, hit)
+	}
+}
+
+// hitWall leaves a mark where the bullet hit and decides what happens next:
+// through the wall if there's penetration left, off it at a glancing angle,
+// otherwise the bullet stops
+func (b *Bullet) hitWall(world *physics.World, hit physics.RayHit) {
+	Impacts.Spawn(hit.Point, hit.Normal, b.Def.ImpactColor)
+
+	if exit, ok := b.penetrate(world); ok {
+		Impacts.Spawn(exit, b.Direction, b.Def.ImpactColor)
+		b.Position = exit
+		b.Damage = int(float32(b.Damage) * b.Def.PenetrationDamage)
+		return
+	}
+	if b.ricochet(hit.Normal) {
+		b.Position = rl.Vector2Add(hit.Point, rl.Vector2Scale(hit.Normal, 0.5)) // Off the surface, so it isn't hit again
+		return
+	}
+	b.IsActive = false
+}
+
+// penetrate looks for the far side of the wall the bullet is entering, within
+// the penetration it has left, and uses up what it takes to get there
+func (b *Bullet) penetrate(world *physics.World) (rl.Vector2, bool) {
+	for depth := float32(penetrationStep); depth <= b.Penetration; depth += penetrationStep {
+		point := rl.Vector2Add(b.Position, rl.Vector2Scale(b.Direction, depth))
+		if !world.IsSolidAt(point) {
+			b.Penetration -= depth
+			return point, true
+		}
 	}
+	return rl.Vector2{}, false
 }
 
-// Update bullet position based on its speed and direction
-func (b *Bullet) Update() {
-	b.Position.X += b.Direction.X * b.Speed
+// ricochet bounces the bullet off a surface facing normal if it hit at a
+// shallow enough angle and has ricochets left
+func (b *Bullet) ricochet(normal rl.Vector2) bool {
+	if b.Ricochets <= 0 {
+		return false
+	}
+	// Angle between the bullet's path and the surface, 0 for a graze and 90 head-on
+	incidence := min(-rl.Vector2DotProduct(b.Direction, normal), 1)
+	if float32(math.Asin(float64(incidence)))*180/math.Pi > b.Def.RicochetAngle {
+		return false
+	}
+	b.Direction = rl.Vector2Reflect(b.Direction, normal)
+	b.Speed *= b.Def.RicochetSpeed
+	b.Ricochets--
+	return true
+}
+
+// Hit damages zombie, unless this bullet already hit it, and uses up the
+// bullet once it can't pierce any more
+func (b *Bullet) Hit(zombie *Zombie) bool {
+	for _, hit := range b.hits {
+		if hit == zombie {
+			return false
+		}
+	}
+	b.hits = append(b.hits, zombie)
+	Particles.Burst(bloodSpray, b.Position, b.Direction)
+	zombie.TakeDamage(b.Damage)
+	if b.Pierce > 0 {
+		b.Pierce--
+	} else {
+		b.IsActive = false
+	}
+	return true
 }
 
 func (b *Bullet) Draw() {
 	if b.IsActive {
-		rl.DrawCircleV(b.Position, 5, rl.Red) 
+		rl.DrawCircleV(b.Position, b.Def.Radius, b.Def.Color)
 	}
 }
@@ -0,0 +1,156 @@
+package gameobjects
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// BuffKind is what a timed buff changes about the player
+type BuffKind int
+
+const (
+	SpeedBuff  BuffKind = iota // Walk and run speed, Amount 0.5 is +50%
+	DamageBuff                 // Bullet and melee damage, Amount 0.5 is +50%
+	RegenBuff                  // Health restored per second
+)
+
+// Buff is a temporary change to the player, given by a consumable
+type Buff struct {
+	Name     string
+	Kind     BuffKind
+	Amount   float32
+	Duration float32 // Seconds the buff lasts
+}
+
+// ActiveBuff is a buff the player is under
+type ActiveBuff struct {
+	Buff
+	Remaining float32 // Seconds left
+}
+
+// ConsumableDef describes what using an item does
+type ConsumableDef struct {
+	Name  string
+	Heal  float64 // Health restored right away, capped at MaxHealth
+	Buffs []Buff  // Timed buffs started when used
+}
+
+// consumableDefs holds every usable item, keyed by item name
+var consumableDefs = map[string]*ConsumableDef{
+	"Health Pack": {Name: "Health Pack", Heal: 35},
+	"Med Kit":     {Name: "Med Kit", Heal: 100},
+	"Bandage": {
+		Name:  "Bandage",
+		Heal:  10,
+		Buffs: []Buff{{Name: "Bandaged", Kind: RegenBuff, Amount: 3, Duration: 10}},
+	},
+	"Adrenaline": {
+		Name: "Adrenaline",
+		Buffs: []Buff{
+			{Name: "Adrenaline", Kind: SpeedBuff, Amount: 0.4, Duration: 12},
+			{Name: "Rage", Kind: DamageBuff, Amount: 0.5, Duration: 12},
+		},
+	},
+}
+
+// defaultHealthPack is used by HealthPack items without a def of their own
+var defaultHealthPack = consumableDefs["Health Pack"]
+
+// RegisterConsumable adds a usable item, or replaces the one with the same name
+func RegisterConsumable(def ConsumableDef) {
+	consumableDefs[def.Name] = &def
+}
+
+// ConsumableDefFor returns what using the item with this name does
+func ConsumableDefFor(name string) (*ConsumableDef, bool) {
+	def, ok := consumableDefs[name]
+	return def, ok
+}
+
+// itemEffects holds what using an item of each type does, returning false
+// when it has no effect and shouldn't be used up
+var itemEffects = map[ItemType]func(p *Player, item Item) bool{
+	HealthPack: func(p *Player, item Item) bool {
+		def, ok := ConsumableDefFor(item.Name)
+		if !ok {
+			def = defaultHealthPack
+		}
+		if p.Health >= p.MaxHealth && len(def.Buffs) == 0 {
+			return false // Don't waste it
+		}
+		p.consume(def)
+		return true
+	},
+	Consumable: func(p *Player, item Item) bool {
+		def, ok := ConsumableDefFor(item.Name)
+		if !ok {
+			return false
+		}
+		p.consume(def)
+		return true
+	},
+}
+
+// UseSelectedItem uses the item in the selected inventory slot, removing it
+// from the slot if it had an effect
+func (p *Player) UseSelectedItem() bool {
+	item := p.Inventory.Slots[p.Inventory.SelectedSlot]
+	effect, ok := itemEffects[item.Type]
+	if !ok || !effect(p, item) {
+		return false
+	}
+	p.Inventory.RemoveSelected(1)
+	return true
+}
+
+// consume heals the player and starts the def's buffs, with a popup for each
+func (p *Player) consume(def *ConsumableDef) {
+	if def.Heal > 0 {
+		healed := min(def.Heal, p.MaxHealth-p.Health)
+		p.Health += healed
+		p.popup(fmt.Sprintf("+%.0f HP", healed), rl.Green)
+	}
+	for _, buff := range def.Buffs {
+		p.AddBuff(buff)
+		p.popup(buff.Name, rl.SkyBlue)
+	}
+	p.flash(rl.Green)
+}
+
+// AddBuff starts a buff, restarting it if one with the same name is active
+func (p *Player) AddBuff(buff Buff) {
+	for i := range p.Buffs {
+		if p.Buffs[i].Name == buff.Name {
+			p.Buffs[i] = ActiveBuff{Buff: buff, Remaining: buff.Duration}
+			return
+		}
+	}
+	p.Buffs = append(p.Buffs, ActiveBuff{Buff: buff, Remaining: buff.Duration})
+}
+
+// buffScale returns the multiplier from every active buff of kind, 1 when there are none
+func (p *Player) buffScale(kind BuffKind) float32 {
+	scale := float32(1)
+	for _, buff := range p.Buffs {
+		if buff.Kind == kind {
+			scale += buff.Amount
+		}
+	}
+	return scale
+}
+
+// updateBuffs applies regeneration and drops buffs that have run out
+func (p *Player) updateBuffs(dt float32) {
+	active := p.Buffs[:0]
+	for _, buff := range p.Buffs {
+		if buff.Kind == RegenBuff && p.Health > 0 {
+			p.Health = min(p.Health+float64(buff.Amount*dt), p.MaxHealth)
+		}
+		buff.Remaining -= dt
+		if buff.Remaining > 0 {
+			active = append(active, buff)
+		}
+	}
+	p.Buffs = active
+}
@@ -0,0 +1,119 @@
+package gameobjects
+
+import (
+	"platformer-game/rendering"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	maxParticles     = 2048
+	landingDustSpeed = 450.0 // Falling faster than this, in pixels per second, kicks up dust on landing
+	glowSize         = 32    // Pixels across the generated glow texture
+)
+
+// Particles holds the particle effects of the running level
+var Particles = rendering.NewParticleSystem(maxParticles)
+
+// Particle effects, the glow texture is filled in by InitEffects
+var (
+	muzzleFlash = &rendering.EmitterDef{
+		MinSpeed: 40, MaxSpeed: 160,
+		Spread:  50,
+		Drag:    8,
+		MinLife: 0.05, MaxLife: 0.1,
+		StartColor: rl.Color{R: 255, G: 240, B: 180, A: 255},
+		EndColor:   rl.Color{R: 255, G: 120, B: 0, A: 0},
+		StartSize:  9, EndSize: 3,
+		Additive: true,
+		Burst:    6,
+	}
+	bloodSpray = &rendering.EmitterDef{
+		MinSpeed: 60, MaxSpeed: 240,
+		Spread:  70,
+		Gravity: 900,
+		MinLife: 0.3, MaxLife: 0.6,
+		StartColor: rl.Color{R: 170, G: 0, B: 0, A: 255},
+		EndColor:   rl.Color{R: 90, G: 0, B: 0, A: 0},
+		StartSize:  3, EndSize: 1.5,
+		Burst: 10,
+	}
+	bloodBurst = &rendering.EmitterDef{
+		MinSpeed: 80, MaxSpeed: 280,
+		Spread:  360,
+		Gravity: 900,
+		MinLife: 0.4, MaxLife: 0.8,
+		StartColor: rl.Color{R: 150, G: 0, B: 0, A: 255},
+		EndColor:   rl.Color{R: 70, G: 0, B: 0, A: 0},
+		StartSize:  4, EndSize: 2,
+		Shape: rendering.SquareParticle,
+		Burst: 24,
+	}
+	deathMist = &rendering.EmitterDef{
+		MinSpeed: 10, MaxSpeed: 40,
+		Spread:  90,
+		Gravity: -20,
+		Drag:    1,
+		MinLife: 0.6, MaxLife: 1.2,
+		StartColor: rl.Color{R: 80, G: 90, B: 60, A: 140},
+		EndColor:   rl.Color{R: 40, G: 40, B: 40, A: 0},
+		StartSize:  4, EndSize: 12,
+		Rate: 25,
+	}
+	landingDust = &rendering.EmitterDef{
+		MinSpeed: 40, MaxSpeed: 120,
+		Spread:  30,
+		Gravity: -40,
+		Drag:    4,
+		MinLife: 0.3, MaxLife: 0.5,
+		StartColor: rl.Color{R: 190, G: 180, B: 160, A: 180},
+		EndColor:   rl.Color{R: 150, G: 140, B: 120, A: 0},
+		StartSize:  3, EndSize: 9,
+		Burst: 8,
+	}
+	impactSparks = rendering.EmitterDef{
+		MinSpeed: 90, MaxSpeed: 270,
+		Spread:  145,
+		Gravity: 600,
+		MinLife: 0.25, MaxLife: 0.35,
+		StartSize: 5, EndSize: 2,
+		Shape:    rendering.StreakParticle,
+		Additive: true,
+		Burst:    6,
+	}
+)
+
+// sparkDefs holds a copy of impactSparks for each spark color in use
+var sparkDefs = map[rl.Color]*rendering.EmitterDef{}
+
+var glow rl.Texture2D // Soft round dot that muzzle flashes are drawn with
+
+// InitEffects makes the textures particle effects are drawn with, once the window is open
+func InitEffects() {
+	image := rl.GenImageGradientRadial(glowSize, glowSize, 0, rl.White, rl.Blank)
+	glow = rl.LoadTextureFromImage(image)
+	rl.UnloadImage(image)
+	muzzleFlash.Texture = glow
+}
+
+// UnloadEffects clears every effect and frees the textures made by InitEffects
+func UnloadEffects() {
+	Particles.Clear()
+	Impacts.Clear()
+	muzzleFlash.Texture = rl.Texture2D{}
+	rl.UnloadTexture(glow)
+	glow = rl.Texture2D{}
+}
+
+// sparks returns the spark effect in color
+func sparks(color rl.Color) *rendering.EmitterDef {
+	def, ok := sparkDefs[color]
+	if !ok {
+		copied := impactSparks
+		copied.StartColor = color
+		copied.EndColor = rl.Color{R: color.R, G: color.G / 2, B: 0, A: 0}
+		def = &copied
+		sparkDefs[color] = def
+	}
+	return def
+}
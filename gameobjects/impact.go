@@ -0,0 +1,66 @@
+package gameobjects
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	maxDecals     = 96   // Marks kept at once, the oldest goes first
+	decalLifetime = 20.0 // Seconds a mark stays
+	decalFade     = 3.0  // Seconds it takes to fade out at the end
+	decalRadius   = 2.5
+)
+
+// decal is a bullet mark left on the level
+type decal struct {
+	position rl.Vector2
+	age      float32
+}
+
+// ImpactEffects are the marks bullets leave where they hit the level, the
+// sparks go to Particles
+type ImpactEffects struct {
+	decals []decal // Oldest first
+}
+
+// Impacts holds the impact effects of the running level
+var Impacts ImpactEffects
+
+// Spawn throws sparks off a surface facing normal at point and leaves a mark there
+func (e *ImpactEffects) Spawn(point, normal rl.Vector2, color rl.Color) {
+	Particles.Burst(sparks(color), point, normal)
+
+	if len(e.decals) == maxDecals {
+		copy(e.decals, e.decals[1:])
+		e.decals = e.decals[:maxDecals-1]
+	}
+	e.decals = append(e.decals, decal{position: point})
+}
+
+// Update ages the marks, dropping the ones that are done
+func (e *ImpactEffects) Update(dt float32) {
+	decals := e.decals[:0]
+	for _, d := range e.decals {
+		d.age += dt
+		if d.age < decalLifetime {
+			decals = append(decals, d)
+		}
+	}
+	e.decals = decals
+}
+
+// Draw draws the marks inside view, the part of the world on screen
+func (e *ImpactEffects) Draw(view rl.Rectangle) {
+	for _, d := range e.decals {
+		if !rl.CheckCollisionPointRec(d.position, view) {
+			continue
+		}
+		alpha := min((decalLifetime-d.age)/decalFade, 1)
+		rl.DrawCircleV(d.position, decalRadius, rl.Fade(rl.Black, 0.6*alpha))
+	}
+}
+
+// Clear removes every mark, when the level ends
+func (e *ImpactEffects) Clear() {
+	e.decals = nil
+}
@@ -1,6 +1,13 @@
 package gameobjects
 
-import rl "github.com/gen2brain/raylib-go/raylib"
+import (
+	"fmt"
+
+	"platformer-game/input"
+	"platformer-game/resources"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
 
 type ItemType int
 
@@ -8,12 +15,32 @@ const (
 	Weapon ItemType = iota
 	HealthPack
 	Other
+	Consumable // Used up for a buff, see consumableDefs
+	Ammo       // Goes straight to the active gun's spare rounds when picked up
+	Currency   // Goes straight to the player's coins when picked up
 )
 
+// Item is a stack of Count units of the same item, an empty slot has a Count of 0
 type Item struct {
-	Type  ItemType
-	Name  string
-	Image rl.Texture2D
+	ID          string // Items with the same ID stack, see itemDefs
+	Type        ItemType
+	Name        string
+	Image       rl.Texture2D
+	TexturePath string // Where Image was loaded from
+	Count       int
+}
+
+// Empty reports whether this is an empty slot
+func (item Item) Empty() bool {
+	return item.Count <= 0
+}
+
+// MaxStack returns how many units of the item fit in one slot
+func (item Item) MaxStack() int {
+	if def, ok := ItemDefFor(item.ID); ok && def.MaxStack > 1 {
+		return def.MaxStack
+	}
+	return 1
 }
 
 type Inventory struct {
@@ -21,93 +48,315 @@ type Inventory struct {
 	MaxSlots     int
 	IsOpen       bool
 	SelectedSlot int
+	dragged      Item // Stack held by the mouse, out of any slot
+	dragFrom     int  // Slot the dragged stack came from
+	justPicked   bool // The button that picked up the stack hasn't been let go yet
 }
 
 func NewInventory(maxSlots int) Inventory {
-    slots := make([]Item, maxSlots)
-    for i := range slots {
-        slots[i] = Item{Type: Other} // Initialize each slot as empty
-    }
-    return Inventory{
-        Slots:    slots,
-        MaxSlots: maxSlots,
-    }
+	slots := make([]Item, maxSlots) // Every slot starts out empty
+	return Inventory{
+		Slots:    slots,
+		MaxSlots: maxSlots,
+	}
 }
 
-
-// Method to add an item to the inventory
-func (inv *Inventory) AddItem(item Item) bool {
-    for i := 0; i < inv.MaxSlots; i++ {
-        if inv.Slots[i].Type == Other {
-            inv.Slots[i] = item // Place item in the empty slot
-            return true
-        }
-    }
-    return false // Return false if inventory is full
+// AddItem puts item's units in the inventory, topping up stacks of the same
+// item before filling empty slots, and returns how many didn't fit. Each slot
+// it fills loads its own reference to the item's texture.
+func (inv *Inventory) AddItem(item Item) int {
+	left := item.Count
+	maxStack := item.MaxStack()
+	for i := range inv.Slots {
+		slot := &inv.Slots[i]
+		if left == 0 {
+			break
+		}
+		if !slot.Empty() && slot.ID == item.ID && slot.Count < maxStack {
+			added := min(maxStack-slot.Count, left)
+			slot.Count += added
+			left -= added
+		}
+	}
+	for i := range inv.Slots {
+		slot := &inv.Slots[i]
+		if left == 0 {
+			break
+		}
+		if slot.Empty() {
+			*slot = item
+			slot.Count = min(maxStack, left)
+			slot.Image = resources.Texture(item.TexturePath)
+			left -= slot.Count
+		}
+	}
+	return left
 }
 
+// RemoveSelected takes count units from the selected slot, emptying it and
+// releasing its texture once none are left
+func (inv *Inventory) RemoveSelected(count int) {
+	slot := &inv.Slots[inv.SelectedSlot]
+	slot.Count -= count
+	if slot.Count > 0 {
+		return
+	}
+	if slot.Image.ID != 0 {
+		resources.ReleaseTexture(slot.Image)
+	}
+	*slot = Item{}
+}
 
+// Layout of the inventory window on the screen
+const (
+	invX        = 100 // Top-left corner of the first slot
+	invY        = 100
+	slotSize    = 50
+	padding     = 10
+	slotsPerRow = 5
+)
 
 func (inv *Inventory) UpdateSelection() {
-    slotsPerRow := 5 // Number of slots per row
-    if rl.IsKeyPressed(rl.KeyRight) {
-        inv.SelectedSlot = (inv.SelectedSlot + 1) % inv.MaxSlots
-    }
-    if rl.IsKeyPressed(rl.KeyLeft) {
-        inv.SelectedSlot = (inv.SelectedSlot - 1 + inv.MaxSlots) % inv.MaxSlots
-    }
-    if rl.IsKeyPressed(rl.KeyDown) {
-        inv.SelectedSlot = (inv.SelectedSlot + slotsPerRow) % inv.MaxSlots
-    }
-    if rl.IsKeyPressed(rl.KeyUp) {
-        inv.SelectedSlot = (inv.SelectedSlot - slotsPerRow + inv.MaxSlots) % inv.MaxSlots
-    }
+	if input.Pressed(input.InventoryRight) {
+		inv.SelectedSlot = (inv.SelectedSlot + 1) % inv.MaxSlots
+	}
+	if input.Pressed(input.InventoryLeft) {
+		inv.SelectedSlot = (inv.SelectedSlot - 1 + inv.MaxSlots) % inv.MaxSlots
+	}
+	if input.Pressed(input.InventoryDown) {
+		inv.SelectedSlot = (inv.SelectedSlot + slotsPerRow) % inv.MaxSlots
+	}
+	if input.Pressed(input.InventoryUp) {
+		inv.SelectedSlot = (inv.SelectedSlot - slotsPerRow + inv.MaxSlots) % inv.MaxSlots
+	}
 }
 
+// slotRect returns where slot i is drawn on the screen
+func slotRect(i int) rl.Rectangle {
+	return rl.Rectangle{
+		X:      float32(invX + (i%slotsPerRow)*(slotSize+padding)),
+		Y:      float32(invY + (i/slotsPerRow)*(slotSize+padding)),
+		Width:  slotSize,
+		Height: slotSize,
+	}
+}
 
+// windowRect returns the panel behind the slots, dropping an item outside it
+// puts the item back into the world
+func (inv *Inventory) windowRect() rl.Rectangle {
+	rows := (inv.MaxSlots + slotsPerRow - 1) / slotsPerRow
+	return rl.Rectangle{
+		X:      invX - padding,
+		Y:      invY - padding,
+		Width:  float32(slotsPerRow*(slotSize+padding) + padding),
+		Height: float32(rows*(slotSize+padding) + padding),
+	}
+}
 
+// slotAt returns the slot under the mouse, or -1
+func (inv *Inventory) slotAt(mouse rl.Vector2) int {
+	for i := range inv.Slots {
+		if rl.CheckCollisionPointRec(mouse, slotRect(i)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Dragging reports whether a stack is being dragged with the mouse
+func (inv *Inventory) Dragging() bool {
+	return !inv.dragged.Empty()
+}
+
+// Dragged returns the stack being dragged with the mouse, empty if there isn't one
+func (inv *Inventory) Dragged() Item {
+	return inv.dragged
+}
+
+// UpdateMouse handles the mouse while the inventory is open: left-drag moves
+// a stack onto another slot, merging or swapping with what's there, and
+// right-drag takes half a stack. Clicking without dragging keeps the stack on
+// the mouse until the next click. A stack let go of outside the window is
+// returned so it can be dropped into the world.
+func (inv *Inventory) UpdateMouse() (dropped Item) {
+	mouse := rl.GetMousePosition()
+	hovered := inv.slotAt(mouse)
+
+	switch {
+	case !inv.Dragging() && hovered >= 0 && rl.IsMouseButtonPressed(rl.MouseLeftButton):
+		// Pick up the whole stack
+		inv.SelectedSlot = hovered
+		if !inv.Slots[hovered].Empty() {
+			inv.dragged = inv.Slots[hovered]
+			inv.dragFrom = hovered
+			inv.justPicked = true
+			inv.Slots[hovered] = Item{}
+		}
+
+	case !inv.Dragging() && hovered >= 0 && rl.IsMouseButtonPressed(rl.MouseRightButton):
+		// Split off half the stack, rounding the half left behind down
+		slot := &inv.Slots[hovered]
+		if slot.Count > 1 {
+			inv.dragged = *slot
+			inv.dragged.Count = (slot.Count + 1) / 2
+			inv.dragged.Image = resources.Texture(slot.TexturePath) // The split needs its own reference
+			inv.dragFrom = hovered
+			inv.justPicked = true
+			slot.Count -= inv.dragged.Count
+		}
+
+	case inv.Dragging() && (rl.IsMouseButtonReleased(rl.MouseLeftButton) || rl.IsMouseButtonReleased(rl.MouseRightButton)):
+		switch {
+		case inv.justPicked && hovered == inv.dragFrom:
+			inv.justPicked = false // Clicked, not dragged
+		case hovered >= 0:
+			inv.SelectedSlot = hovered
+			inv.place(hovered)
+		case !rl.CheckCollisionPointRec(mouse, inv.windowRect()):
+			dropped = inv.dragged
+			inv.dragged = Item{}
+		default:
+			dropped = inv.CancelDrag() // Let go between slots
+		}
+	}
+	return dropped
+}
+
+// place puts the dragged stack into slot i: into it if it's empty, onto it if
+// it holds the same item, swapping with it otherwise. Whatever doesn't fit
+// stays on the mouse.
+func (inv *Inventory) place(i int) {
+	slot := &inv.Slots[i]
+	switch {
+	case slot.Empty():
+		*slot = inv.dragged
+		inv.dragged = Item{}
+	case slot.ID == inv.dragged.ID:
+		moved := min(slot.MaxStack()-slot.Count, inv.dragged.Count)
+		slot.Count += moved
+		inv.dragged.Count -= moved
+		if inv.dragged.Empty() {
+			resources.ReleaseTexture(inv.dragged.Image) // Merged, the slot keeps its own reference
+			inv.dragged = Item{}
+		}
+	default:
+		*slot, inv.dragged = inv.dragged, *slot
+		inv.dragFrom = i
+	}
+	inv.justPicked = false
+}
+
+// CancelDrag puts the dragged stack back, in the slot it came from if there's
+// room. It returns whatever didn't fit anywhere, to be dropped into the world.
+func (inv *Inventory) CancelDrag() Item {
+	if !inv.Dragging() {
+		return Item{}
+	}
+	inv.justPicked = false
+	if slot := inv.Slots[inv.dragFrom]; slot.Empty() || slot.ID == inv.dragged.ID {
+		inv.place(inv.dragFrom)
+	}
+	if !inv.Dragging() {
+		return Item{}
+	}
+	left := inv.AddItem(inv.dragged) // Loads references of its own for the slots it fills
+	dropped := inv.dragged
+	dropped.Count = left
+	inv.dragged = Item{}
+	if left == 0 {
+		resources.ReleaseTexture(dropped.Image)
+		return Item{}
+	}
+	return dropped
+}
 
 func (inv *Inventory) DrawInventory() {
-    invX, invY := 100, 100 // Position of the inventory on the screen
-    slotSize := 50
-    padding := 10
+	mouse := rl.GetMousePosition()
+	hovered := inv.slotAt(mouse)
 
-    for i, item := range inv.Slots {
-        x := invX + (i % 5) * (slotSize + padding) // Arrange items in a grid
-        y := invY + (i / 5) * (slotSize + padding)
-        rl.DrawRectangle(int32(x), int32(y), int32(slotSize), int32(slotSize), rl.Gray)
+	rl.DrawRectangleRec(inv.windowRect(), rl.Fade(rl.Black, 0.5))
 
+	for i, item := range inv.Slots {
+		rect := slotRect(i)
 
-		// Draw the slot background with a highlight if it's selected
-		if i == inv.SelectedSlot {
-			rl.DrawRectangle(int32(x), int32(y), int32(slotSize), int32(slotSize), rl.Yellow) // Highlighted color
-		} else {
-			rl.DrawRectangle(int32(x), int32(y), int32(slotSize), int32(slotSize), rl.Gray) // Normal color
+		// Draw the slot background with a highlight if it's selected or under the mouse
+		switch {
+		case i == inv.SelectedSlot:
+			rl.DrawRectangleRec(rect, rl.Yellow) // Highlighted color
+		case i == hovered:
+			rl.DrawRectangleRec(rect, rl.LightGray)
+		default:
+			rl.DrawRectangleRec(rect, rl.Gray) // Normal color
 		}
+		drawStack(item, rl.Vector2{X: rect.X, Y: rect.Y})
+	}
+
+	// The dragged stack follows the mouse, otherwise the hovered item gets a tooltip
+	if inv.Dragging() {
+		drawStack(inv.dragged, rl.Vector2{X: mouse.X - slotSize/2, Y: mouse.Y - slotSize/2})
+	} else if hovered >= 0 && !inv.Slots[hovered].Empty() {
+		drawTooltip(inv.Slots[hovered], mouse)
+	}
+}
 
-        if item.Type != Other && item.Image.ID != 0 {
-            // Calculate the scale factor to fit the texture within the slot
-            textureWidth := float32(item.Image.Width)
-            textureHeight := float32(item.Image.Height)
-            scale := float32(slotSize) / max(textureWidth, textureHeight) // Scale based on the largest dimension
+// drawStack draws an item's icon fitted into a slot at position, with its count
+func drawStack(item Item, position rl.Vector2) {
+	x, y := int32(position.X), int32(position.Y)
+	if !item.Empty() && item.Image.ID != 0 {
+		// Calculate the scale factor to fit the texture within the slot
+		textureWidth := float32(item.Image.Width)
+		textureHeight := float32(item.Image.Height)
+		scale := float32(slotSize) / max(textureWidth, textureHeight) // Scale based on the largest dimension
+
+		// Calculate new width and height to maintain aspect ratio
+		drawWidth := int32(textureWidth * scale)
+		drawHeight := int32(textureHeight * scale)
+
+		// Center the texture within the slot
+		drawX := x + (slotSize-drawWidth)/2
+		drawY := y + (slotSize-drawHeight)/2
+
+		rl.DrawTextureEx(item.Image, rl.Vector2{X: float32(drawX), Y: float32(drawY)}, 0, scale, rl.White)
+	}
+
+	// Stack size in the bottom-right corner
+	if item.Count > 1 {
+		count := fmt.Sprint(item.Count)
+		rl.DrawText(count, x+slotSize-4-rl.MeasureText(count, 10), y+slotSize-14, 10, rl.White)
+	}
+}
 
-            // Calculate new width and height to maintain aspect ratio
-            drawWidth := int32(textureWidth * scale)
-            drawHeight := int32(textureHeight * scale)
+// drawTooltip shows an item's name and description next to the mouse
+func drawTooltip(item Item, mouse rl.Vector2) {
+	title := item.Name
+	if item.Count > 1 {
+		title = fmt.Sprintf("%s (%d)", item.Name, item.Count)
+	}
+	description := ""
+	if def, ok := ItemDefFor(item.ID); ok {
+		description = def.Description
+	}
 
-            // Center the texture within the slot
-            drawX := int32(x) + (int32(slotSize)-drawWidth)/2
-            drawY := int32(y) + (int32(slotSize)-drawHeight)/2
+	width := max(float32(rl.MeasureText(title, 10)), float32(rl.MeasureText(description, 10))) + 12
+	height := float32(20)
+	if description != "" {
+		height += 12
+	}
+	x, y := mouse.X+14, mouse.Y+14
+	x = min(x, float32(rl.GetScreenWidth())-width) // Keep it on screen
+	y = min(y, float32(rl.GetScreenHeight())-height)
 
-            rl.DrawTextureEx(item.Image, rl.Vector2{X: float32(drawX), Y: float32(drawY)}, 0, scale, rl.White)
-        }
-    }
+	rl.DrawRectangleRec(rl.Rectangle{X: x, Y: y, Width: width, Height: height}, rl.Fade(rl.Black, 0.85))
+	rl.DrawText(title, int32(x)+6, int32(y)+5, 10, rl.White)
+	if description != "" {
+		rl.DrawText(description, int32(x)+6, int32(y)+17, 10, rl.LightGray)
+	}
 }
 
 // Helper function to get the max of two float32 values
 func max(a, b float32) float32 {
-    if a > b {
-        return a
-    }
-    return b
+	if a > b {
+		return a
+	}
+	return b
 }
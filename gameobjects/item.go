@@ -1,28 +1,216 @@
 package gameobjects
 
 import (
-	
+	"fmt"
+	"math"
+
+	"platformer-game/physics"
+	"platformer-game/resources"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// ItemDef describes an item that can be carried
+type ItemDef struct {
+	ID          string
+	Name        string
+	Type        ItemType
+	Texture     string // Icon, also shown when the item lies in the world
+	MaxStack    int    // Units that fit in one inventory slot, 0 and 1 don't stack
+	Description string
+}
+
+// itemDefs holds every known item by ID, items without a def don't stack
+var itemDefs = map[string]*ItemDef{
+	"sword":       {ID: "sword", Name: "Sword", Type: Weapon, Texture: "assets/sword.png", MaxStack: 1, Description: "Swing to hit every zombie in front of you"},
+	"pistol":      {ID: "pistol", Name: "Pistol", Type: Weapon, MaxStack: 1, Description: "Hard-hitting rounds that pass through a zombie"},
+	"health_pack": {ID: "health_pack", Name: "Health Pack", Type: HealthPack, Texture: "assets/healthpack.png", MaxStack: 5, Description: "Restores 35 health"},
+	"med_kit":     {ID: "med_kit", Name: "Med Kit", Type: HealthPack, Texture: "assets/medkit.png", MaxStack: 3, Description: "Restores all health"},
+	"bandage":     {ID: "bandage", Name: "Bandage", Type: HealthPack, Texture: "assets/bandage.png", MaxStack: 10, Description: "Restores a little health, then more over time"},
+	"ammo":        {ID: "ammo", Name: "Ammo", Type: Ammo, Texture: "assets/ammo.png", MaxStack: 240, Description: "Spare rounds for the gun in your hands"},
+	"coin":        {ID: "coin", Name: "Coins", Type: Currency, Texture: "assets/coin.png", MaxStack: 999, Description: "Zombies carry some loose change"},
+	"adrenaline":  {ID: "adrenaline", Name: "Adrenaline", Type: Consumable, Texture: "assets/adrenaline.png", MaxStack: 3, Description: "Faster and harder-hitting for a while"},
+}
+
+// RegisterItem adds an item, or replaces the one with the same ID
+func RegisterItem(def ItemDef) {
+	itemDefs[def.ID] = &def
+}
+
+// ItemDefFor returns the item registered with id
+func ItemDefFor(id string) (*ItemDef, bool) {
+	def, ok := itemDefs[id]
+	return def, ok
+}
+
+// Look of items lying in the world
+const (
+	itemBobHeight   = 4.0  // Pixels an item floats up and down
+	itemBobSpeed    = 2.5  // Bobs per second, roughly
+	itemGlowPadding = 10.0 // How far the glow reaches past the item
+	itemFriction    = 0.85 // Horizontal speed kept per step once an item lands
+)
+
 type WorldItem struct {
-	Position rl.Vector2
-	Texture  rl.Texture2D
-	Type     ItemType // Referencing ItemType from Inventory
-	Name     string
+	Position    rl.Vector2 // Top-left corner
+	Velocity    rl.Vector2 // Items thrown out by zombies fly a little before landing
+	Texture     rl.Texture2D
+	TexturePath string
+	ID          string
+	Type        ItemType // Referencing ItemType from Inventory
+	Name        string
+	Count       int     // Units picked up at once
+	age         float32 // Seconds since spawning, drives the bobbing
 }
 
-func NewWorldItem(x, y float32, itemType ItemType, name string, texturePath string) WorldItem {
+func NewWorldItem(x, y float32, id string, itemType ItemType, name string, texturePath string, count int) WorldItem {
 	return WorldItem{
-		Position: rl.NewVector2(x, y),
-		Texture:  rl.LoadTexture(texturePath),
-		Type:     itemType,
-		Name:     name,
+		Position:    rl.NewVector2(x, y),
+		Texture:     resources.Texture(texturePath), // Shared with every item using the same file,
+		TexturePath: texturePath,
+		ID:          id,
+		Type:        itemType,
+		Name:        name,
+		Count:       count,
+	}
+}
+
+// NewWorldItemFromDef creates count units of a registered item centered on
+// position, for drops that don't come from a level
+func NewWorldItemFromDef(def *ItemDef, position rl.Vector2, count int) WorldItem {
+	item := NewWorldItem(position.X, position.Y, def.ID, def.Type, def.Name, def.Texture, count)
+	item.Position.X -= float32(item.Texture.Width) / 2
+	item.Position.Y -= float32(item.Texture.Height) / 2
+	return item
+}
+
+// Item returns the world item as an inventory stack, without a texture of its
+// own: AddItem loads one for every slot it fills
+func (item *WorldItem) Item() Item {
+	return Item{
+		ID:          item.ID,
+		Type:        item.Type,
+		Name:        item.Name,
+		TexturePath: item.TexturePath,
+		Count:       item.Count,
+	}
+}
+
+// Bounds returns the item's box in the world, without the bobbing
+func (item *WorldItem) Bounds() rl.Rectangle {
+	return rl.Rectangle{
+		X:      item.Position.X,
+		Y:      item.Position.Y,
+		Width:  float32(item.Texture.Width),
+		Height: float32(item.Texture.Height),
+	}
+}
+
+// Center returns the middle of the item's box
+func (item *WorldItem) Center() rl.Vector2 {
+	bounds := item.Bounds()
+	return rl.Vector2{X: bounds.X + bounds.Width/2, Y: bounds.Y + bounds.Height/2}
+}
+
+// Update lets the item fall onto whatever is below it
+func (item *WorldItem) Update(dt float32, world *physics.World) {
+	item.age += dt
+	item.Velocity.Y += gravity * dt
+	box, contacts := world.Move(item.Bounds(), rl.Vector2Scale(item.Velocity, dt), false)
+	item.Position = rl.Vector2{X: box.X, Y: box.Y}
+	if contacts.OnGround || contacts.HitCeiling {
+		item.Velocity.Y = 0
+	}
+	if contacts.HitLeft || contacts.HitRight {
+		item.Velocity.X = 0
+	}
+	if contacts.OnGround {
+		item.Velocity.X *= itemFriction
 	}
 }
 
-func (item *WorldItem) Draw() {
-    // fmt.Println("Drawing item:", item.Name, "at position:", item.Position)
-    rl.DrawTexture(item.Texture, int32(item.Position.X), int32(item.Position.Y), rl.White)
+// Draw draws the item bobbing over a soft glow, brighter when highlighted
+func (item *WorldItem) Draw(highlighted bool) {
+	phase := item.age*itemBobSpeed*2*math.Pi + item.Position.X // Items next to each other don't bob in step
+	bob := (float32(math.Sin(float64(phase))) - 1) * itemBobHeight / 2
+	center := item.Center()
+	center.Y += bob
+
+	glow := rl.Fade(rl.Gold, 0.25)
+	if highlighted {
+		glow = rl.Fade(rl.Gold, 0.5)
+	}
+	radius := max(float32(item.Texture.Width), float32(item.Texture.Height))/2 + itemGlowPadding
+	rl.DrawCircleGradient(int32(center.X), int32(center.Y), radius, glow, rl.Fade(rl.Gold, 0))
+
+	rl.DrawTexture(item.Texture, int32(item.Position.X), int32(item.Position.Y+bob), rl.White)
+	if item.Count > 1 {
+		count := fmt.Sprintf("x%d", item.Count)
+		rl.DrawText(count, int32(item.Position.X)+item.Texture.Width-rl.MeasureText(count, 10)/2, int32(item.Position.Y+bob)+item.Texture.Height-8, 10, rl.White)
+	}
+}
+
+// WorldItems holds every item lying in the level
+type WorldItems struct {
+	items []*WorldItem
+}
+
+// Spawn adds an item to the world, taking over its texture reference
+func (w *WorldItems) Spawn(item WorldItem) *WorldItem {
+	w.items = append(w.items, &item)
+	return &item
+}
+
+// Despawn removes an item from the world and releases its texture
+func (w *WorldItems) Despawn(item *WorldItem) {
+	for i, other := range w.items {
+		if other == item {
+			resources.ReleaseTexture(item.Texture)
+			w.items = append(w.items[:i], w.items[i+1:]...)
+			return
+		}
+	}
+}
+
+// Clear despawns every item
+func (w *WorldItems) Clear() {
+	for _, item := range w.items {
+		resources.ReleaseTexture(item.Texture)
+	}
+	w.items = nil
+}
+
+// All returns the items in the world, in spawn order
+func (w *WorldItems) All() []*WorldItem {
+	return w.items
+}
+
+// Nearest returns the item closest to position within reach, or nil
+func (w *WorldItems) Nearest(position rl.Vector2, reach float32) *WorldItem {
+	var nearest *WorldItem
+	best := reach
+	for _, item := range w.items {
+		if distance := rl.Vector2Distance(position, item.Center()); distance < best {
+			nearest, best = item, distance
+		}
+	}
+	return nearest
+}
+
+// Update moves every item
+func (w *WorldItems) Update(dt float32, world *physics.World) {
+	for _, item := range w.items {
+		item.Update(dt, world)
+	}
+}
+
+// Draw draws the items in view, highlighted glowing brighter
+func (w *WorldItems) Draw(view rl.Rectangle, highlighted *WorldItem) {
+	for _, item := range w.items {
+		bounds := item.Bounds()
+		grown := rl.Rectangle{X: bounds.X - itemGlowPadding, Y: bounds.Y - itemGlowPadding, Width: bounds.Width + 2*itemGlowPadding, Height: bounds.Height + 2*itemGlowPadding}
+		if rl.CheckCollisionRecs(grown, view) {
+			item.Draw(item == highlighted)
+		}
+	}
 }
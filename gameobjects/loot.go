@@ -0,0 +1,86 @@
+package gameobjects
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// LootEntry is an item a loot table can drop
+type LootEntry struct {
+	Item   string // Item ID, empty for a roll that drops nothing
+	Weight int    // Chance against the other entries, ignored for guaranteed drops
+	Min    int    // Units dropped, picked between Min and Max
+	Max    int
+}
+
+// LootTable is what a zombie drops when it dies
+type LootTable struct {
+	Guaranteed []LootEntry // Always dropped
+	Rolls      int         // Entries picked by weight, each roll can pick the same one
+	Entries    []LootEntry
+}
+
+// Drop is a stack of an item rolled from a loot table
+type Drop struct {
+	Item  string
+	Count int
+}
+
+// Check makes sure every item the table can drop is registered
+func (t LootTable) Check() error {
+	for _, entry := range append(t.Guaranteed, t.Entries...) {
+		if _, ok := ItemDefFor(entry.Item); entry.Item != "" && !ok {
+			return fmt.Errorf("unknown loot item %q", entry.Item)
+		}
+	}
+	return nil
+}
+
+// Roll picks the drops: every guaranteed entry, then Rolls weighted picks
+func (t LootTable) Roll() []Drop {
+	var drops []Drop
+	for _, entry := range t.Guaranteed {
+		drops = entry.add(drops)
+	}
+
+	total := 0
+	for _, entry := range t.Entries {
+		total += entry.Weight
+	}
+	if total <= 0 {
+		return drops
+	}
+	for i := 0; i < t.Rolls; i++ {
+		pick := rand.Intn(total)
+		for _, entry := range t.Entries {
+			pick -= entry.Weight
+			if pick < 0 {
+				drops = entry.add(drops)
+				break
+			}
+		}
+	}
+	return drops
+}
+
+// add appends a stack of the entry's item, unless it's a roll for nothing.
+// The same item rolled twice drops as one bigger stack.
+func (e LootEntry) add(drops []Drop) []Drop {
+	if e.Item == "" {
+		return drops
+	}
+	count := e.Min
+	if e.Max > e.Min {
+		count += rand.Intn(e.Max - e.Min + 1)
+	}
+	if count <= 0 {
+		return drops
+	}
+	for i := range drops {
+		if drops[i].Item == e.Item {
+			drops[i].Count += count
+			return drops
+		}
+	}
+	return append(drops, Drop{Item: e.Item, Count: count})
+}
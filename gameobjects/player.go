@@ -2,7 +2,11 @@ package gameobjects
 
 import (
 	"fmt"
+	"platformer-game/input"
+	"platformer-game/physics"
 	"platformer-game/rendering"
+	"platformer-game/resources"
+	"slices"
 	"time"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
@@ -21,99 +25,238 @@ const (
 	Resting
 	Sleeping
 	Dying
+	Swinging
 )
+
+// playerAnimations is the animation file with a clip for every player state
+const playerAnimations = "assets/animations/player.json"
+
+// playerClips maps each state to its clip in playerAnimations
+var playerClips = map[PlayerState]string{
+	Idle:            "idle",
+	Walking:         "walk",
+	Running:         "run",
+	Shooting:        "shoot",
+	Sitting:         "sit",
+	SittingShooting: "sit_shoot",
+	Jumping:         "jump",
+	Resting:         "rest",
+	Sleeping:        "sleep",
+	Dying:           "die",
+	Swinging:        "swing",
+}
+
 const (
-	jumpVelocity = -5.0 // Initial upward velocity for jumping
-	gravity      = 800  // Gravity value, pulling player down each frame
-	groundYPos   = 0    // The ground level, adjust to your world height
+	jumpVelocity       = -550.0 // Initial upward velocity for jumping, in pixels per second
+	gravity            = 1400.0 // Gravity pulling the player down, in pixels per second squared
+	ascentGravityScale = 0.6    // Lighter gravity while rising gives the jump a floatier apex
+	walkSpeed          = 150.0  // Walking speed in pixels per second
+	runSpeed           = 400.0  // Running speed in pixels per second
+	playerHitboxWidth  = 50.0   // Width of the collision box, narrower than the sprite
+	dropThroughTime    = 0.25   // Seconds one-way platforms are ignored after dropping down
+	flashDuration      = 0.3    // Seconds the player is tinted after using an item
+	popupDuration      = 1.0    // Seconds a popup floats above the player
+	popupRise          = 40.0   // Pixels a popup rises over its lifetime
+	muzzleHeight       = 10.0   // How far above the player's center the gun is held
+	muzzleReach        = 45.0   // How far from the shoulder bullets leave the barrel
+	groundYPos         = 0      // The ground level, adjust to your world height
 )
 
 type Player struct {
-	Position              rl.Vector2
-	Speed                 rl.Vector2
-	Acceleration          rl.Vector2
-	Width, Height         float32
-	Color                 rl.Color
-	FacingRight           bool           // Direction the player is facing
-	CurrentFrame          int            // Current frame index for animation
-	FrameCounter          int            // Counter to control frame switch timing
-	State                 PlayerState    // Current animation state
-	IdleTimer             time.Time      // Timer for idle state
-	RestTimer             time.Time      // Timer for resting state
-	WalkFrames            []rl.Texture2D // Frames for walking animation
-	RunFrames             []rl.Texture2D // Frames for running animation
-	IdleFrames            []rl.Texture2D // Frames for idle animation
-	ShootFrames           []rl.Texture2D // Frames for shooting animation
-	SittingFrames         []rl.Texture2D // Frames for sitting animation
-	SittingShootingFrames []rl.Texture2D // Frames for sitting shooting animation
-	JumpFrames            []rl.Texture2D // Frames for jumping animation
-	RestingFrames         []rl.Texture2D // Frames for resting animation
-	SleepingFrames        []rl.Texture2D // Frames for sleeping animation
-	DyingFrames           []rl.Texture2D // Frames for dying animation
-	Bullets               []*Bullet      // Add bullets slice
-	switchDown            bool           // Indicates when to start descending
+	Position      rl.Vector2
+	Speed         rl.Vector2
+	Acceleration  rl.Vector2
+	Width, Height float32
+	Color         rl.Color
+	FacingRight   bool               // Direction the player is facing
+	Aim           rl.Vector2         // Unit vector shots are fired along, zero to fire straight ahead
+	Anim          rendering.Animator // Plays the clip for the current state
+	State         PlayerState        // Current animation state
+	IdleTimer     time.Time          // Timer for idle state
+	RestTimer     time.Time          // Timer for resting state
+	Bullets       []*Bullet          // Add bullets slice
+	OnGround      bool               // Standing on something after the last update
+	dropTimer     float32            // Seconds left of falling through one-way platforms
+	Gun           *Gun               // Gun fired by Shoot, from the held item or the default
+	Guns          map[string]*Gun    // Every gun used so far, keeping its ammo while not held
+	Melee         *MeleeDef          // Held melee weapon, clicking swings it instead of firing
+	swingCooldown float32            // Seconds until the next swing can start
+	swingHits     []*Zombie          // Zombies already hit by the current swing
+	Buffs         []ActiveBuff       // Timed buffs from consumables
+	flashColor    rl.Color           // Tint shown briefly as feedback
+	flashTimer    float32            // Seconds left of the tint
+	popups        []popup            // Text floating up from the player
 
 	// Sounds
-	WalkSound  rl.Sound
-	RunSound   rl.Sound
-	ShootSound rl.Sound
+	WalkSound rl.Sound
+	RunSound  rl.Sound
 
 	// New attributes
 	Health    float64 // Player health
 	MaxHealth float64 // Maximum health to keep track for the health bar
-	Inventory  Inventory
-	HeldItem Item // The currently held item
+	Inventory Inventory
+	HeldItem  Item // The currently held item
+	Coins     int  // Currency picked up from zombies
+	Score     int  // Points for the zombies killed this run
 }
+
 func (p *Player) UpdateHeldItem() {
-    if p.Inventory.Slots[p.Inventory.SelectedSlot].Type != Other {
-        p.HeldItem = p.Inventory.Slots[p.Inventory.SelectedSlot]
-    } else {
-        p.HeldItem = Item{} // No item held if slot is empty
-    }
+	if !p.Inventory.Slots[p.Inventory.SelectedSlot].Empty() {
+		p.HeldItem = p.Inventory.Slots[p.Inventory.SelectedSlot]
+	} else {
+		p.HeldItem = Item{} // No item held if slot is empty
+	}
+
+	// Swing the held melee weapon, otherwise fire the held gun or the default one
+	p.Melee = nil
+	if def, ok := MeleeDefFor(p.HeldItem.Name); ok && p.HeldItem.Type == Weapon {
+		p.Melee = def
+	}
+	name := DefaultWeapon
+	if _, ok := WeaponDefFor(p.HeldItem.Name); ok && p.HeldItem.Type == Weapon {
+		name = p.HeldItem.Name
+	}
+	if p.Gun == nil || p.Gun.Def.Name != name {
+		p.equip(name)
+	}
 }
 
-func (p *Player) Shoot() {
-	if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
-		bulletPosition := p.Position
-		bulletPosition.Y += p.Height / 2 // Adjust to shoot from the middle
-		newBullet := NewBullet(bulletPosition.X, bulletPosition.Y, 10, p.FacingRight)
-		p.Bullets = append(p.Bullets, newBullet)
+// Collect takes items that don't go in the inventory, like ammo and coins,
+// reporting whether item was one of them
+func (p *Player) Collect(item Item) bool {
+	switch item.Type {
+	case Ammo:
+		p.Gun.Reserve += item.Count
+		p.popup(fmt.Sprintf("+%d %s", item.Count, item.Name), rl.Yellow)
+	case Currency:
+		p.Coins += item.Count
+		p.popup(fmt.Sprintf("+%d %s", item.Count, item.Name), rl.Gold)
+	default:
+		return false
+	}
+	return true
+}
 
-		// Play shoot sound
-		if !rl.IsSoundPlaying(p.ShootSound) {
-			rl.PlaySound(p.ShootSound)
-		}
+// equip makes the named gun the active one, it keeps the ammo it had last time
+func (p *Player) equip(name string) {
+	if p.Gun != nil {
+		p.Gun.StopSound()
 	}
+	p.Gun = p.GunFor(name)
 }
-func (p *Player) IsGameOver() bool {
 
-	return p.Health <= 0
+// GunFor returns the player's gun of the named weapon, giving it to them with
+// full ammo the first time. It returns nil for names that aren't guns.
+func (p *Player) GunFor(name string) *Gun {
+	if gun, ok := p.Guns[name]; ok {
+		return gun
+	}
+	def, ok := WeaponDefFor(name)
+	if !ok {
+		return nil
+	}
+	gun := newGun(def)
+	p.Guns[name] = gun
+	return gun
 }
 
-func (p *Player) Unload() {
-	for _, frame := range p.WalkFrames {
-		rl.UnloadTexture(frame)
+// triggerDown reports whether the fire button is held, the mouse belongs to the
+// inventory while it's open
+func (p *Player) triggerDown() bool {
+	return input.Down(input.Fire) && !p.Inventory.IsOpen
+}
+
+// firePressed reports whether fire was pressed since the last update, without
+// consuming the press
+func (p *Player) firePressed() bool {
+	return input.Latched(input.Fire) && !p.Inventory.IsOpen
+}
+
+// Shoot fires the active gun if the trigger is pulled: held down for
+// automatic guns, clicked for the rest. An empty magazine starts a reload.
+func (p *Player) Shoot(world *physics.World) {
+	pressed := p.firePressed()
+	input.Consume(input.Fire)
+	if p.Melee != nil {
+		return // Clicks swing the melee weapon instead, see Update
+	}
+	if !pressed && !(p.Gun.Def.Automatic && p.triggerDown()) {
+		return
+	}
+	if p.Gun.Ammo == 0 {
+		p.Gun.Reload()
+		return
+	}
+	if !p.Gun.Ready() {
+		return
+	}
+
+	// Fired from the shoulder and carried out to the muzzle, so a gun poking
+	// into a wall hits the wall instead of coming out the other side
+	aim := p.aimDirection()
+	bullet := p.Gun.Fire(p.shoulder(), aim)
+	bullet.travel(muzzleReach, world)
+	Particles.Burst(muzzleFlash, rl.Vector2Add(p.shoulder(), rl.Vector2Scale(aim, muzzleReach)), aim)
+	bullet.Damage = int(float32(bullet.Damage) * p.buffScale(DamageBuff))
+	p.Bullets = append(p.Bullets, bullet)
+}
+
+// AimAt points the gun at target, a point in the world
+func (p *Player) AimAt(target rl.Vector2) {
+	toTarget := rl.Vector2Subtract(target, p.shoulder())
+	if rl.Vector2Length(toTarget) < 1 {
+		return // Right on the shoulder, keep the last aim
 	}
-	for _, frame := range p.RunFrames {
-		rl.UnloadTexture(frame)
+	p.Aim = rl.Vector2Normalize(toTarget)
+}
+
+// aimDirection returns where shots go: along Aim, or straight ahead without one
+func (p *Player) aimDirection() rl.Vector2 {
+	if p.Aim != (rl.Vector2{}) {
+		return p.Aim
 	}
-	for _, frame := range p.IdleFrames {
-		rl.UnloadTexture(frame)
+	if p.FacingRight {
+		return rl.Vector2{X: 1}
 	}
-	for _, frame := range p.ShootFrames {
-		rl.UnloadTexture(frame)
+	return rl.Vector2{X: -1}
+}
+
+// shoulder returns the point the gun pivots around when aiming
+func (p *Player) shoulder() rl.Vector2 {
+	return rl.Vector2{X: p.Position.X, Y: p.Position.Y - muzzleHeight}
+}
+
+// Hitbox returns the player's collision box, Position is the center of the sprite
+func (p *Player) Hitbox() rl.Rectangle {
+	return rl.Rectangle{
+		X:      p.Position.X - playerHitboxWidth/2,
+		Y:      p.Position.Y - p.Height/2,
+		Width:  playerHitboxWidth,
+		Height: p.Height,
+	}
+}
+
+func (p *Player) IsGameOver() bool {
+
+	return p.Health <= 0
+}
+
+// Unload releases the sprite sheets and sounds used by the player
+func (p *Player) Unload() {
+	if p.Anim.Set != nil {
+		p.Anim.Set.Unload()
 	}
 	// Unload sounds
-	rl.UnloadSound(p.WalkSound)
-	rl.UnloadSound(p.RunSound)
-	rl.UnloadSound(p.ShootSound)
+	resources.ReleaseSound(p.WalkSound)
+	resources.ReleaseSound(p.RunSound)
+	for _, gun := range p.Guns {
+		gun.unload()
+	}
 }
 
 var PlayerInstance Player
 
-func InitPlayer(worldWidth, worldHeight int) {
-
-	rl.InitAudioDevice() // Initialize audio device
+func InitPlayer(worldWidth, worldHeight int) error {
 	PlayerInstance = Player{
 		Position:     rl.NewVector2(100, float32(worldHeight-50)),
 		Speed:        rl.NewVector2(0, 0),
@@ -121,145 +264,61 @@ func InitPlayer(worldWidth, worldHeight int) {
 		Width:        113,
 		Height:       113,
 		Color:        rl.White,
-		CurrentFrame: 0,
-		FrameCounter: 0,
 		State:        Idle,
 		FacingRight:  true,
-		Health:       100, // Initialize with full health
-		MaxHealth:    100, // Set maximum health
-		Inventory: NewInventory(10), // Initialize with 10 slots
+		Health:       100,              // Initialize with full health
+		MaxHealth:    100,              // Set maximum health
+		Inventory:    NewInventory(10), // Initialize with 10 slots
 	}
 	// Load sounds
-	PlayerInstance.WalkSound = rl.LoadSound("assets/sounds/walking.mp3")
-	PlayerInstance.RunSound = rl.LoadSound("assets/sounds/running.mp3")
-	PlayerInstance.ShootSound = rl.LoadSound("assets/sounds/machineguneffect.wav")
-
-	// Load sprite sheet
-	spriteSheet := rendering.LoadSpriteSheet("assets/sprites/shooterspritesheet.png")
-	spriteSheet2 := rendering.LoadSpriteSheet("assets/sprites/shooterspritesheet2.png")
-
-	// Load walking frames
-	walkingFrames := []rl.Rectangle{
-		{X: 309, Y: 301, Width: 63, Height: 136},  // Frame 1
-		{X: 500, Y: 301, Width: 66, Height: 136},  // Frame 2
-		{X: 690, Y: 303, Width: 72, Height: 134},  // Frame 3
-		{X: 878, Y: 302, Width: 72, Height: 136},  // Frame 4
-		{X: 1075, Y: 299, Width: 70, Height: 138}, // Frame 5
-	}
-	for _, frame := range walkingFrames {
-		PlayerInstance.WalkFrames = append(PlayerInstance.WalkFrames, spriteSheet.ImageAt(frame, rl.Blank))
-	}
-
-	// Load running frames
-	runningFrames := []rl.Rectangle{
-		{X: 267, Y: 525, Width: 76, Height: 122},  // Frame 1
-		{X: 456, Y: 535, Width: 78, Height: 122},  // Frame 2
-		{X: 644, Y: 535, Width: 84, Height: 122},  // Frame 3
-		{X: 840, Y: 525, Width: 80, Height: 122},  // Frame 4
-		{X: 1042, Y: 533, Width: 68, Height: 124}, // Frame 5
-	}
-	for _, frame := range runningFrames {
-		PlayerInstance.RunFrames = append(PlayerInstance.RunFrames, spriteSheet.ImageAt(frame, rl.Blank))
-	}
-
-	// Load idle frames
-	idleFrames := []rl.Rectangle{
-		{X: 296, Y: 71, Width: 94, Height: 134},  // Frame 1
-		{X: 488, Y: 71, Width: 94, Height: 134},  // Frame 2
-		{X: 681, Y: 69, Width: 94, Height: 136},  // Frame 3
-		{X: 873, Y: 69, Width: 94, Height: 136},  // Frame 4
-		{X: 1063, Y: 69, Width: 94, Height: 136}, // Frame 5
-		{X: 1256, Y: 71, Width: 93, Height: 134}, // Frame 6
-	}
-	for _, frame := range idleFrames {
-		PlayerInstance.IdleFrames = append(PlayerInstance.IdleFrames, spriteSheet.ImageAt(frame, rl.Blank))
-	}
-
-	// Load shooting frames
-	shooting1Frames := []rl.Rectangle{
-		{X: 294, Y: 739, Width: 95, Height: 130},  // Frame 1
-		{X: 487, Y: 739, Width: 108, Height: 130}, // Frame 2
-		{X: 677, Y: 739, Width: 125, Height: 130}, // Frame 3
-		{X: 869, Y: 739, Width: 102, Height: 131}, // Frame 4
-		{X: 300, Y: 951, Width: 102, Height: 130}, // Frame 5
-		{X: 492, Y: 951, Width: 111, Height: 130}, // Frame 6
-		{X: 683, Y: 951, Width: 130, Height: 130}, // Frame 7
-		{X: 877, Y: 951, Width: 106, Height: 130}, // Frame 8
-	}
-	for _, frame := range shooting1Frames {
-		PlayerInstance.ShootFrames = append(PlayerInstance.ShootFrames, spriteSheet.ImageAt(frame, rl.Blank))
-	}
-
-	// Load Sitting frames
-	sittingFrames := []rl.Rectangle{
-		{X: 234, Y: 82, Width: 75, Height: 88}, // Frame 1
-		{X: 394, Y: 83, Width: 75, Height: 87}, // Frame 2
-		{X: 555, Y: 85, Width: 75, Height: 86}, // Frame 3
-	}
-	for _, frame := range sittingFrames {
-		PlayerInstance.SittingFrames = append(PlayerInstance.SittingFrames, spriteSheet2.ImageAt(frame, rl.Blank))
-	}
+	PlayerInstance.WalkSound = resources.Sound("assets/sounds/walking.mp3")
+	PlayerInstance.RunSound = resources.Sound("assets/sounds/running.mp3")
 
-	//Load Sitting Shooting frames
-	sittingShootingFrames := []rl.Rectangle{
-		{X: 242, Y: 275, Width: 85, Height: 89},  // Frame 1
-		{X: 399, Y: 275, Width: 84, Height: 89},  // Frame 2
-		{X: 560, Y: 275, Width: 110, Height: 89}, // Frame 3
-	}
-	for _, frame := range sittingShootingFrames {
-		PlayerInstance.SittingShootingFrames = append(PlayerInstance.SittingShootingFrames, spriteSheet2.ImageAt(frame, rl.Blank))
-	}
-
-	//Jumping frames
-	jumpingFrames := []rl.Rectangle{
-		{X: 240, Y: 444, Width: 78, Height: 103}, // Frame 1
-		{X: 401, Y: 450, Width: 80, Height: 96},  // Frame 2
-		{X: 561, Y: 434, Width: 79, Height: 113}, // Frame 3
-		{X: 722, Y: 444, Width: 78, Height: 98},  // Frame 4
-		{X: 1043, Y: 457, Width: 68, Height: 89}, // Frame 5
-
-	}
-	for _, frame := range jumpingFrames {
-		PlayerInstance.JumpFrames = append(PlayerInstance.JumpFrames, spriteSheet2.ImageAt(frame, rl.Blank))
-	}
+	// Start out with the default gun
+	PlayerInstance.Guns = map[string]*Gun{}
+	PlayerInstance.equip(DefaultWeapon)
 
-	//Resting frames
-	restingFrames := []rl.Rectangle{
-		{X: 240, Y: 621, Width: 78, Height: 102}, // Frame 1
-		{X: 400, Y: 626, Width: 78, Height: 97},  // Frame 2
-		{X: 559, Y: 644, Width: 71, Height: 79},  // Frame 3
-		{X: 686, Y: 651, Width: 87, Height: 72},  // Frame 4
+	// Load the animation clips for every state
+	animations, err := rendering.LoadAnimations(playerAnimations)
+	if err != nil {
+		PlayerInstance.Unload()
+		return err
 	}
+	PlayerInstance.Anim = rendering.NewAnimator(animations, playerClips[Idle])
+	return nil
+}
 
-	for _, frame := range restingFrames {
-		PlayerInstance.RestingFrames = append(PlayerInstance.RestingFrames, spriteSheet2.ImageAt(frame, rl.Blank))
-	}
+// popup is text floating up from the player, like "+35 HP"
+type popup struct {
+	text  string
+	color rl.Color
+	timer float32 // Seconds left
+}
 
-	//Sleeping frames
-	sleepingFrames := []rl.Rectangle{
-		{X: 231, Y: 864, Width: 113, Height: 32}, // Frame 1
-		{X: 390, Y: 847, Width: 115, Height: 49}, // Frame 2
-		{X: 541, Y: 825, Width: 124, Height: 71}, // Frame 3
-		{X: 711, Y: 864, Width: 114, Height: 32}, // Frame 4
-		{X: 869, Y: 863, Width: 114, Height: 33}, // Frame 5
+// popup shows text floating up from the player for a moment
+func (p *Player) popup(text string, color rl.Color) {
+	p.popups = append(p.popups, popup{text: text, color: color, timer: popupDuration})
+}
 
-	}
-	for _, frame := range sleepingFrames {
-		PlayerInstance.SleepingFrames = append(PlayerInstance.SleepingFrames, spriteSheet2.ImageAt(frame, rl.Blank))
-	}
+// flash tints the player with color for a moment
+func (p *Player) flash(color rl.Color) {
+	p.flashColor = color
+	p.flashTimer = flashDuration
+}
 
-	//Dying frames
-	dyingFrames := []rl.Rectangle{
-		{X: 315, Y: 952, Width: 92, Height: 128},
-		{X: 504, Y: 943, Width: 94, Height: 137},
-		{X: 651, Y: 984, Width: 128, Height: 96},
-		{X: 814, Y: 1041, Width: 160, Height: 39},
+// updateFeedback counts down the flash and popups
+func (p *Player) updateFeedback(dt float32) {
+	if p.flashTimer > 0 {
+		p.flashTimer -= dt
 	}
-
-	for _, frame := range dyingFrames {
-		PlayerInstance.DyingFrames = append(PlayerInstance.DyingFrames, spriteSheet2.ImageAt(frame, rl.Blank))
+	active := p.popups[:0]
+	for _, popup := range p.popups {
+		popup.timer -= dt
+		if popup.timer > 0 {
+			active = append(active, popup)
+		}
 	}
-
+	p.popups = active
 }
 
 /***********************************STATES*********************************************** */
@@ -267,8 +326,7 @@ func InitPlayer(worldWidth, worldHeight int) {
 func (p *Player) setState(state PlayerState) {
 	if p.State != state {
 		p.State = state
-		p.CurrentFrame = 0
-		p.FrameCounter = 0
+		p.Anim.Play(p.clip(state))
 	}
 
 	// Reset timers when changing to idle, resting, or sleeping states
@@ -283,27 +341,41 @@ func (p *Player) setState(state PlayerState) {
 	}
 }
 
+// clip returns the animation for state, firing plays the active gun's
+func (p *Player) clip(state PlayerState) string {
+	switch {
+	case state == Shooting && p.Gun.Def.Clip != "":
+		return p.Gun.Def.Clip
+	case state == SittingShooting && p.Gun.Def.CrouchClip != "":
+		return p.Gun.Def.CrouchClip
+	case state == Swinging && p.Melee != nil && p.Melee.Clip != "":
+		return p.Melee.Clip
+	}
+	return playerClips[state]
+}
+
 /***********************************UPDATE*********************************************** */
 
-func (p *Player) Update(worldHeight int, worldWidth int, zombies []*Zombie) {
+// Update advances the player by dt seconds, colliding with the world's geometry
+func (p *Player) Update(dt float32, world *physics.World, zombies []*Zombie) {
 	// fmt.Println("players starting out y position: ", p.Position.Y)
 
 	// Update bullets
 	for _, bullet := range p.Bullets {
 		if bullet.IsActive {
-			bullet.Update()
+			bullet.Update(dt, world)
 
 			// Here we are checking if bullet hits any zombie
 			for _, zombie := range zombies {
 				if zombie.IsAlive && rl.CheckCollisionPointCircle(bullet.Position, zombie.Position, zombie.Width/2) {
-					zombie.TakeDamage(20) // Adjust damage as needed
-					bullet.IsActive = false
-					break
+					if bullet.Hit(zombie) && !bullet.IsActive {
+						break
+					}
 				}
 			}
 
 			// Deactivate bullet if it goes out of bounds
-			if bullet.Position.X < 0 || bullet.Position.X > float32(worldWidth) {
+			if bullet.Position.X < 0 || bullet.Position.X > world.Width || bullet.Position.Y < 0 || bullet.Position.Y > world.Height {
 				bullet.IsActive = false
 			}
 		}
@@ -311,7 +383,7 @@ func (p *Player) Update(worldHeight int, worldWidth int, zombies []*Zombie) {
 
 	//print inventory
 	//if inventory is not empty then print the item inside
-	if ( len(p.Inventory.Slots) != 0){
+	if len(p.Inventory.Slots) != 0 {
 		// fmt.Println("Inventory:", p.Inventory)
 
 	}
@@ -324,59 +396,50 @@ func (p *Player) Update(worldHeight int, worldWidth int, zombies []*Zombie) {
 		}
 	}
 	p.Bullets = activeBullets
-	// Check if player is on the ground
-	onGround := p.Position.Y >= float32(worldHeight)-p.Height
-
-	// Apply gravity and handle jumping
-	if !onGround || p.State == Jumping {
-		// Print player's position for debugging
-		// fmt.Println("Player Y Position:", p.Position.Y)
-
-		// Apply gravity effect based on ascending or descending state
-		if p.Speed.Y < 0 && !p.switchDown { // Ascending
-			fmt.Println("Ascending")
-
-			// Switch to descending if near the apex
-			if p.Speed.Y >= -0.5 { // Lower threshold for more gradual transition
-				fmt.Println("Switching down")
-				p.switchDown = true
-			}
-			p.Speed.Y += gravity * 0.000001 // Reduce gravity effect while ascending
-		} else { // Descending
-			p.Speed.Y += gravity * 0.005 // Normal gravity effect for descent
-		}
-
-		// Update the player's vertical position with the adjusted speed
-		p.Position.Y += p.Speed.Y
 
+	p.updateBuffs(dt)
+	p.updateFeedback(dt)
 
+	// Reloading and the time between shots and swings
+	if p.swingCooldown > 0 {
+		p.swingCooldown -= dt
+	}
+	p.Gun.Update(dt)
+	if input.Consume(input.Reload) {
+		p.Gun.Reload()
 	}
 
-	// If player is grounded and was jumping, reset to Idle and reset switchDown
-	if p.Position.Y >= float32(worldHeight)-p.Height {
-		p.Position.Y = float32(worldHeight) - p.Height
-		p.Speed.Y = 0
-		p.switchDown = false // Reset switchDown for the next jump
-		if p.State == Jumping {
-			p.setState(Idle) // Reset to Idle after landing
-		}
+	// Ground contact comes from the previous update's move
+	onGround := p.OnGround
+	jumpPressed := input.Consume(input.Jump)
+	// Run with the run button, or by pushing the stick all the way
+	running := input.Down(input.Run) || max(input.Tilt(input.MoveLeft), input.Tilt(input.MoveRight)) >= input.RunThreshold
+	if p.dropTimer > 0 {
+		p.dropTimer -= dt
 	}
 
 	// Player state logic based on key inputs, prioritizing crouching
 	switch {
-	case rl.IsKeyDown(rl.KeyLeftControl):
+	case p.State == Swinging && !p.Anim.Finished():
+		// Swings play out before anything else, see swing for the hits
+		p.Speed.X = 0
+
+	case p.Melee != nil && p.firePressed() && p.swingCooldown <= 0:
+		// Start a melee swing
+		p.setState(Swinging)
+		p.Anim.Restart()
+		p.Speed.X = 0
+		p.swingCooldown = p.Melee.Cooldown
+		p.swingHits = p.swingHits[:0]
+		rl.StopSound(p.WalkSound)
+		rl.StopSound(p.RunSound)
+
+	case input.Down(input.Crouch):
 		// Crouching has priority, halts forward movement
-		if rl.IsMouseButtonDown(rl.MouseLeftButton) {
-			fmt.Println("Sitting and shooting")
+		if p.triggerDown() && p.Melee == nil {
 			p.setState(SittingShooting)
-			p.Shoot() // Call shoot when sitting and shooting
-			//call shoot method simul
 			p.Speed.X = 0 // Halt horizontal movement
-			rl.StopSound(p.WalkSound)
 
-			if !rl.IsSoundPlaying(p.ShootSound) {
-				rl.PlaySound(p.ShootSound)
-			}
 			//stop walking sound
 			rl.StopSound(p.WalkSound)
 
@@ -386,88 +449,60 @@ func (p *Player) Update(worldHeight int, worldWidth int, zombies []*Zombie) {
 			rl.StopSound(p.WalkSound)
 		}
 
-		// When initiating the jump, set a lower initial speed
-	case rl.IsKeyPressed(rl.KeySpace) && onGround:
-		// Jump initiation
-		p.setState(Jumping)
-		p.Speed.Y = -2.0 // Lower initial jump speed for a shorter jump
-		fmt.Println("Jumping")
-
-		// Apply gravity and handle jumping
-		if !onGround || p.State == Jumping {
-			// Print player's position for debugging
-			fmt.Println(p.Position.Y)
-
-			if p.Speed.Y < 0 && !p.switchDown { // Ascending
-				fmt.Println("Ascending")
-				if p.Speed.Y > -0.1 {
-					fmt.Println("Switching down")
-					p.switchDown = true
-				}
-				p.Speed.Y += p.Acceleration.Y * 0.001 // Maintain slow upward deceleration
-			} else { // Descending
-				p.Speed.Y += p.Acceleration.Y * 0.1 // Slightly faster but controlled descent
-			}
-			p.Position.Y += p.Speed.Y
+		// Jumping while crouched drops down through one-way platforms
+		if jumpPressed && onGround {
+			p.dropTimer = dropThroughTime
 		}
 
-		// If player lands on the ground, reset to Idle and reset switchDown
-		if p.Position.Y >= float32(worldHeight)-p.Height {
-			p.Position.Y = float32(worldHeight) - p.Height
-			p.Speed.Y = 0
-			p.switchDown = false // Reset switchDown for the next jump
-			if p.State == Jumping {
-				p.setState(Idle) // Reset to Idle after landing
-			}
-		}
+	case jumpPressed && onGround:
+		// Jump initiation, gravity takes over from the next update
+		p.setState(Jumping)
+		p.Speed.Y = jumpVelocity
 
-	case rl.IsMouseButtonDown(rl.MouseLeftButton) && p.State != Sitting && p.State != SittingShooting:
+	case p.triggerDown() && p.Melee == nil && p.State != Sitting && p.State != SittingShooting:
 		// Shooting (no horizontal movement)
 		p.setState(Shooting)
 		p.Speed.X = 0
-		if !rl.IsSoundPlaying(p.ShootSound) {
-			rl.PlaySound(p.ShootSound)
-		}
 		//stop walking sound
 		rl.StopSound(p.WalkSound)
 		//stop running sound
 		rl.StopSound(p.RunSound)
 
-	case rl.IsKeyDown(rl.KeyD) && rl.IsKeyDown(rl.KeyLeftShift) && p.State != Shooting && p.State != Sitting:
+	case input.Down(input.MoveRight) && running && p.State != Shooting && p.State != Sitting:
 		// Running (right) if not shooting or crouching
 		p.setState(Running)
 		p.FacingRight = true
-		p.Speed.X = 0.2
+		p.Speed.X = runSpeed * p.buffScale(SpeedBuff)
 		if !rl.IsSoundPlaying(p.RunSound) {
 			rl.PlaySound(p.RunSound)
 		}
 		rl.StopSound(p.WalkSound)
 
-	case rl.IsKeyDown(rl.KeyD) && p.State != Shooting && p.State != Sitting && p.State != SittingShooting:
+	case input.Down(input.MoveRight) && p.State != Shooting && p.State != Sitting && p.State != SittingShooting:
 		// Walking (right) if not shooting or crouching
 		p.setState(Walking)
 		p.FacingRight = true
-		p.Speed.X = 0.05
+		p.Speed.X = walkSpeed * p.buffScale(SpeedBuff)
 		if !rl.IsSoundPlaying(p.WalkSound) {
 			rl.PlaySound(p.WalkSound)
 		}
 		rl.StopSound(p.RunSound)
 
-	case rl.IsKeyDown(rl.KeyA) && rl.IsKeyDown(rl.KeyLeftShift) && p.State != Shooting && p.State != Sitting:
+	case input.Down(input.MoveLeft) && running && p.State != Shooting && p.State != Sitting:
 		// Running (left) if not shooting or crouching
 		p.setState(Running)
 		p.FacingRight = false
-		p.Speed.X = -0.2
+		p.Speed.X = -runSpeed * p.buffScale(SpeedBuff)
 		if !rl.IsSoundPlaying(p.RunSound) {
 			rl.PlaySound(p.RunSound)
 		}
 		rl.StopSound(p.WalkSound)
 
-	case rl.IsKeyDown(rl.KeyA) && p.State != Shooting && p.State != Sitting && p.State != SittingShooting:
+	case input.Down(input.MoveLeft) && p.State != Shooting && p.State != Sitting && p.State != SittingShooting:
 		// Walking (left) if not shooting or crouching
 		p.setState(Walking)
 		p.FacingRight = false
-		p.Speed.X = -0.05
+		p.Speed.X = -walkSpeed * p.buffScale(SpeedBuff)
 		if !rl.IsSoundPlaying(p.WalkSound) {
 			rl.PlaySound(p.WalkSound)
 		}
@@ -479,126 +514,104 @@ func (p *Player) Update(worldHeight int, worldWidth int, zombies []*Zombie) {
 		p.Speed.X = 0
 		rl.StopSound(p.WalkSound)
 		rl.StopSound(p.RunSound)
-		rl.StopSound(p.ShootSound)
+		p.Gun.StopSound()
 	}
 
-	if !rl.IsMouseButtonDown(rl.MouseLeftButton) {
-		rl.StopSound(p.ShootSound)
+	// Face the side the gun is aimed at, even when moving the other way,
+	// except mid-swing so the swing stays on one side
+	if p.Aim != (rl.Vector2{}) && p.State != Swinging {
+		p.FacingRight = p.Aim.X >= 0
 	}
 
-	// Update horizontal position
-	p.Position.X += p.Speed.X
+	if !p.triggerDown() {
+		p.Gun.StopSound()
+	}
 
-	// this is to constrain player within screen bounds (X-axis)
-	if p.Position.X < 0 {
-		p.Position.X = 0
-	} else if p.Position.X > float32(worldWidth)-p.Width {
-		p.Position.X = float32(worldWidth) - p.Width
+	// Apply gravity, lighter on the way up than on the way down
+	if p.Speed.Y < 0 {
+		p.Speed.Y += gravity * ascentGravityScale * dt
+	} else {
+		p.Speed.Y += gravity * dt
 	}
 
-	// this is to Ensure player doesn't sink below ground level (Y-axis)
-	if p.Position.Y >= float32(worldHeight) {
-		p.Position.Y = float32(worldHeight)
-		p.Speed.Y = 0
+	// Move through the world, stopping at walls, floors and ceilings
+	delta := rl.Vector2{X: p.Speed.X * dt, Y: p.Speed.Y * dt}
+	fallSpeed := p.Speed.Y
+	box, contacts := world.Move(p.Hitbox(), delta, p.dropTimer > 0)
+	p.Position = rl.Vector2{X: box.X + box.Width/2, Y: box.Y + box.Height/2}
+	if contacts.OnGround || contacts.HitCeiling {
+		p.Speed.Y = 0 // Landed, or bumped our head
+	}
+	if contacts.OnGround && !p.OnGround && fallSpeed >= landingDustSpeed {
+		// Dust puffs out both ways from under the feet
+		feet := rl.Vector2{X: p.Position.X, Y: box.Y + box.Height}
+		Particles.Burst(landingDust, feet, rl.Vector2{X: -1})
+		Particles.Burst(landingDust, feet, rl.Vector2{X: 1})
+	}
+	p.OnGround = contacts.OnGround
+
+	// If player lands while jumping, reset to Idle
+	if p.OnGround && p.State == Jumping {
+		p.setState(Idle)
 	}
 
 	// Updating animation frames based on state of the player
-	p.FrameCounter++
-	var frames []rl.Texture2D
-	frameDelay := 300
-	switch p.State {
-		case Walking:
-			frames = p.WalkFrames
-		case Running:
-			frames = p.RunFrames
-		case Shooting:
-			frames = p.ShootFrames
-		case Sitting:
-			frames = p.SittingFrames
-		case SittingShooting:
-			frames = p.SittingShootingFrames
-		case Jumping:
-			frames = p.JumpFrames
-			frameDelay = 500
-		case Resting:
-			frames = p.RestingFrames
-			frameDelay = 5000
-		case Sleeping:
-			frames = p.SleepingFrames
-			frameDelay = 5000
-		case Dying:
-			frames = p.DyingFrames
-			frameDelay = 10000
-		default:
-			frames = p.IdleFrames
-	}
-
-	// Only update frame based on delay
-	if len(frames) > 0 && p.FrameCounter >= frameDelay {
-		p.CurrentFrame = (p.CurrentFrame + 1) % len(frames)
-		p.FrameCounter = 0
+	p.Anim.Update(dt)
+
+	if p.State == Swinging {
+		p.swing(zombies)
 	}
 }
 
-/***********************************DRAW*********************************************** */
-
-func (p *Player) Draw() {
+// swing hits every zombie in the melee weapon's arc while the swing is on one
+// of its active frames, each zombie once per swing
+func (p *Player) swing(zombies []*Zombie) {
+	if p.Melee == nil {
+		return // Weapon put away mid-swing
+	}
+	frame := p.Anim.Frame()
+	if frame < p.Melee.ActiveFrames[0] || frame > p.Melee.ActiveFrames[1] {
+		return
+	}
 
-	var frame rl.Texture2D
-	switch p.State {
-	case Walking:
-		frame = p.WalkFrames[p.CurrentFrame]
-	case Running:
-		frame = p.RunFrames[p.CurrentFrame]
-	case Shooting:
-		frame = p.ShootFrames[p.CurrentFrame]
-	case Sitting:
-		frame = p.SittingFrames[p.CurrentFrame]
-	case SittingShooting:
-		frame = p.SittingShootingFrames[p.CurrentFrame]
-	case Jumping:
-		frame = p.JumpFrames[p.CurrentFrame]
-	case Resting:
-		frame = p.RestingFrames[p.CurrentFrame]
-	case Sleeping:
-		frame = p.SleepingFrames[p.CurrentFrame]
-	case Dying:
-		frame = p.DyingFrames[p.CurrentFrame]
-	default:
-		frame = p.IdleFrames[p.CurrentFrame]
+	for _, zombie := range zombies {
+		if !zombie.IsAlive || !p.Melee.InArc(p.Position, p.FacingRight, zombie) || slices.Contains(p.swingHits, zombie) {
+			continue
+		}
+		p.swingHits = append(p.swingHits, zombie)
+		forward := float32(1)
+		if !p.FacingRight {
+			forward = -1
+		}
+		Particles.Burst(bloodSpray, zombie.Position, rl.Vector2{X: forward})
+		zombie.TakeDamage(int(float32(p.Melee.Damage) * p.buffScale(DamageBuff)))
+		zombie.Knockback(forward * p.Melee.Knockback)
 	}
+}
 
-	if p.HeldItem.Type != Other && p.HeldItem.Image.ID != 0 {
-        heldX := p.Position.X  -10 // Adjust for desired position relative to player
-        heldY := p.Position.Y - 10 // Adjust for desired position relative to player
-        rl.DrawTextureEx(p.HeldItem.Image, rl.Vector2{X: heldX, Y: heldY}, 0, 0.5, rl.White) // Scale to desired size
-    }
+/***********************************DRAW*********************************************** */
 
-	// Source rectangle starts normally
-	sourceRect := rl.Rectangle{X: 0, Y: 0, Width: float32(frame.Width), Height: float32(frame.Height)}
-	// Flip the source width to achieve a horizontal flip
-	if !p.FacingRight {
-		sourceRect.Width = -sourceRect.Width
+func (p *Player) Draw() {
+
+	if !p.HeldItem.Empty() && p.HeldItem.Image.ID != 0 {
+		heldX := p.Position.X - 10                                                           // Adjust for desired position relative to player
+		heldY := p.Position.Y - 10                                                           // Adjust for desired position relative to player
+		rl.DrawTextureEx(p.HeldItem.Image, rl.Vector2{X: heldX, Y: heldY}, 0, 0.5, rl.White) // Scale to desired size
 	}
 
-	// Destination rectangle keeps player position and scale
-	destinationRect := rl.Rectangle{
-		X:      p.Position.X,
-		Y:      p.Position.Y,
-		Width:  p.Width,
-		Height: p.Height,
+	// Draw the current frame, flipped when facing left
+	tint := p.Color
+	if p.flashTimer > 0 {
+		tint = p.flashColor
 	}
+	p.Anim.Draw(p.Position, rl.Vector2{X: p.Width, Y: p.Height}, !p.FacingRight, tint)
 
-	// Draw the current frame with adjusted sourceRect for flipping
-	if frame.ID != 0 { // Ensure the frame texture is loaded
-		rl.DrawTexturePro(
-			frame,
-			sourceRect,      // Flipped if FacingRight is false
-			destinationRect, // Destination position and size on the screen
-			rl.Vector2{X: p.Width / 2, Y: p.Height / 2}, // Origin remains centered
-			0,       // No rotation
-			p.Color, // Tint color
-		)
+	// Popups rise and fade out, newer ones stacked above older ones
+	for i, popup := range p.popups {
+		age := 1 - popup.timer/popupDuration
+		x := int32(p.Position.X) - rl.MeasureText(popup.text, 10)/2
+		y := int32(p.Position.Y-p.Height/2-popupRise*age) - int32(12*i)
+		rl.DrawText(popup.text, x, y, 10, rl.Fade(popup.color, 1-age))
 	}
 
 	// Drawing bullets
@@ -0,0 +1,55 @@
+package gameobjects
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// DefaultProjectile is fired by guns that don't name a projectile of their own
+const DefaultProjectile = "Rifle Round"
+
+// ProjectileDef describes how a kind of bullet looks and behaves when it hits
+// the level. When it runs into a wall it tries to punch through first, then to
+// glance off, and stops if it can do neither.
+type ProjectileDef struct {
+	Name              string
+	Radius            float32
+	Color             rl.Color
+	ImpactColor       rl.Color // Sparks thrown off the walls it hits
+	Penetration       float32  // Pixels of solid wall a bullet can pass through over its life
+	PenetrationDamage float32  // Share of the damage left after passing through a wall
+	Ricochets         int      // Times a bullet can glance off walls
+	RicochetAngle     float32  // Steepest angle off the surface, in degrees, that still glances off
+	RicochetSpeed     float32  // Share of the speed left after glancing off
+}
+
+// projectileDefs holds every kind of bullet, keyed by name
+var projectileDefs = map[string]*ProjectileDef{
+	"Rifle Round": {
+		Name:              "Rifle Round",
+		Radius:            4,
+		Color:             rl.Red,
+		ImpactColor:       rl.Gold,
+		Penetration:       55, // Just over a tile
+		PenetrationDamage: 0.5,
+	},
+	"Pistol Round": {
+		Name:          "Pistol Round",
+		Radius:        3,
+		Color:         rl.Orange,
+		ImpactColor:   rl.Yellow,
+		Ricochets:     2,
+		RicochetAngle: 35,
+		RicochetSpeed: 0.8,
+	},
+}
+
+// RegisterProjectile adds a kind of bullet, or replaces the one with the same name
+func RegisterProjectile(def ProjectileDef) {
+	projectileDefs[def.Name] = &def
+}
+
+// ProjectileDefFor returns the kind of bullet with this name
+func ProjectileDefFor(name string) (*ProjectileDef, bool) {
+	def, ok := projectileDefs[name]
+	return def, ok
+}
@@ -0,0 +1,223 @@
+package gameobjects
+
+import (
+	"math"
+	"math/rand"
+
+	"platformer-game/resources"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// DefaultWeapon is the gun used when the held item isn't one
+const DefaultWeapon = "Machine Gun"
+
+// WeaponDef describes how a gun fires
+type WeaponDef struct {
+	Name            string
+	Damage          int     // Health a bullet takes from each zombie it hits
+	FireRate        float32 // Shots per second
+	Magazine        int     // Rounds per magazine
+	StartingAmmo    int     // Spare rounds carried besides a full magazine
+	ReloadTime      float32 // Seconds to refill the magazine
+	ProjectileSpeed float32 // Pixels per second
+	Spread          float32 // Degrees a shot can stray from where it's aimed, either way
+	Pierce          int     // Extra zombies a bullet passes through
+	Projectile      string  // Kind of bullet fired, see projectileDefs
+	Automatic       bool    // Keeps firing while the button is held, otherwise one shot per click
+	Sound           string  // Played for every shot
+	Clip            string  // Player animation while firing standing up
+	CrouchClip      string  // Player animation while firing crouched
+}
+
+// weaponDefs holds every gun, keyed by the name of the item that gives it
+var weaponDefs = map[string]*WeaponDef{
+	"Machine Gun": {
+		Name:            "Machine Gun",
+		Damage:          20,
+		FireRate:        10,
+		Magazine:        30,
+		StartingAmmo:    240,
+		ReloadTime:      1.6,
+		ProjectileSpeed: 600,
+		Spread:          3,
+		Projectile:      "Rifle Round",
+		Automatic:       true,
+		Sound:           "assets/sounds/machineguneffect.wav",
+		Clip:            "shoot",
+		CrouchClip:      "sit_shoot",
+	},
+	"Pistol": {
+		Name:            "Pistol",
+		Damage:          35,
+		FireRate:        4,
+		Magazine:        12,
+		StartingAmmo:    60,
+		ReloadTime:      1.1,
+		ProjectileSpeed: 700,
+		Spread:          1,
+		Pierce:          1,
+		Projectile:      "Pistol Round",
+		Sound:           "assets/sounds/machineguneffect.wav",
+		Clip:            "shoot",
+		CrouchClip:      "sit_shoot",
+	},
+}
+
+// RegisterWeapon adds a gun, or replaces the one with the same name
+func RegisterWeapon(def WeaponDef) {
+	weaponDefs[def.Name] = &def
+}
+
+// WeaponDefFor returns the gun given by the item with this name
+func WeaponDefFor(name string) (*WeaponDef, bool) {
+	def, ok := weaponDefs[name]
+	return def, ok
+}
+
+// Gun is a weapon the player carries, with its own ammo
+type Gun struct {
+	Def         *WeaponDef
+	Ammo        int     // Rounds in the magazine
+	Reserve     int     // Spare rounds
+	cooldown    float32 // Seconds until the next shot
+	reloadTimer float32 // Seconds left of reloading, 0 when not reloading
+	sound       rl.Sound
+}
+
+func newGun(def *WeaponDef) *Gun {
+	return &Gun{
+		Def:     def,
+		Ammo:    def.Magazine,
+		Reserve: def.StartingAmmo,
+		sound:   resources.Sound(def.Sound),
+	}
+}
+
+// Update counts down the time between shots and finishes reloads
+func (g *Gun) Update(dt float32) {
+	if g.cooldown > 0 {
+		g.cooldown -= dt
+	}
+	if g.reloadTimer > 0 {
+		g.reloadTimer -= dt
+		if g.reloadTimer <= 0 {
+			g.reloadTimer = 0
+			rounds := min(g.Def.Magazine-g.Ammo, g.Reserve)
+			g.Ammo += rounds
+			g.Reserve -= rounds
+		}
+	}
+}
+
+// Reload starts refilling the magazine, if it isn't full and there's ammo to spare
+func (g *Gun) Reload() {
+	if g.Reloading() || g.Ammo == g.Def.Magazine || g.Reserve == 0 {
+		return
+	}
+	g.reloadTimer = g.Def.ReloadTime
+	rl.StopSound(g.sound)
+}
+
+// Reloading reports whether a reload is in progress
+func (g *Gun) Reloading() bool {
+	return g.reloadTimer > 0
+}
+
+// ReloadProgress returns how far along a reload is, from 0 to 1
+func (g *Gun) ReloadProgress() float32 {
+	if !g.Reloading() {
+		return 0
+	}
+	return 1 - g.reloadTimer/g.Def.ReloadTime
+}
+
+// Ready reports whether the gun can fire right now
+func (g *Gun) Ready() bool {
+	return g.cooldown <= 0 && !g.Reloading() && g.Ammo > 0
+}
+
+// Fire shoots one round from position along the unit vector aim, give or
+// take the weapon's spread
+func (g *Gun) Fire(position, aim rl.Vector2) *Bullet {
+	g.Ammo--
+	g.cooldown += 1 / g.Def.FireRate // Keeps the leftover from this step so automatic fire holds its rate
+
+	spread := (rand.Float64()*2 - 1) * float64(g.Def.Spread) * math.Pi / 180
+	angle := math.Atan2(float64(aim.Y), float64(aim.X)) + spread
+	direction := rl.Vector2{X: float32(math.Cos(angle)), Y: float32(math.Sin(angle))}
+
+	if g.Def.Automatic {
+		// The automatic fire sound is long, keep it going instead of restarting it every shot
+		if !rl.IsSoundPlaying(g.sound) {
+			rl.PlaySound(g.sound)
+		}
+	} else {
+		rl.PlaySound(g.sound)
+	}
+	projectile, ok := ProjectileDefFor(g.Def.Projectile)
+	if !ok {
+		projectile = projectileDefs[DefaultProjectile]
+	}
+	return NewBullet(position, direction, g.Def.ProjectileSpeed, g.Def.Damage, g.Def.Pierce, projectile)
+}
+
+// StopSound cuts the firing sound off, when the trigger is released
+func (g *Gun) StopSound() {
+	rl.StopSound(g.sound)
+}
+
+func (g *Gun) unload() {
+	resources.ReleaseSound(g.sound)
+}
+
+// MeleeDef describes how a melee weapon swings
+type MeleeDef struct {
+	Name         string
+	Damage       int     // Health taken from each zombie in the arc
+	Knockback    float32 // Speed zombies are knocked away at, in pixels per second
+	Reach        float32 // Distance from the player's center the swing reaches
+	Arc          float32 // Degrees the swing covers, centered on the facing direction
+	ActiveFrames [2]int  // First and last frame of the swing clip that can hit
+	Cooldown     float32 // Seconds from the start of one swing to the next
+	Clip         string  // Player animation for the swing, played once
+}
+
+// meleeDefs holds every melee weapon, keyed by the name of the item that gives it
+var meleeDefs = map[string]*MeleeDef{
+	"Sword": {
+		Name:         "Sword",
+		Damage:       45,
+		Knockback:    350,
+		Reach:        90,
+		Arc:          150,
+		ActiveFrames: [2]int{2, 3},
+		Cooldown:     0.45,
+		Clip:         "swing",
+	},
+}
+
+// RegisterMeleeWeapon adds a melee weapon, or replaces the one with the same name
+func RegisterMeleeWeapon(def MeleeDef) {
+	meleeDefs[def.Name] = &def
+}
+
+// MeleeDefFor returns the melee weapon given by the item with this name
+func MeleeDefFor(name string) (*MeleeDef, bool) {
+	def, ok := meleeDefs[name]
+	return def, ok
+}
+
+// InArc reports whether a zombie is within the swing of a player at position
+// facing the given way
+func (d *MeleeDef) InArc(position rl.Vector2, facingRight bool, zombie *Zombie) bool {
+	offset := rl.Vector2Subtract(zombie.Position, position)
+	if rl.Vector2Length(offset) > d.Reach+zombie.Archetype.HitboxWidth/2 {
+		return false
+	}
+	if !facingRight {
+		offset.X = -offset.X
+	}
+	angle := math.Atan2(float64(offset.Y), float64(offset.X)) * 180 / math.Pi
+	return math.Abs(angle) <= float64(d.Arc)/2
+}
@@ -2,11 +2,14 @@ package gameobjects
 
 import (
 	"fmt"
+	"platformer-game/physics"
 	"platformer-game/rendering"
+	"platformer-game/resources"
 	"time"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
+
 var gameOver bool // Variable to track game over state
 
 type ZombieState int
@@ -20,12 +23,19 @@ const (
 )
 
 const (
-	stateSwitchDelay = 3 * time.Second // Delay between state switches
-	frameDelay       = 5000          // Default delay for frame updates
-	attackRange      = 50.0            // Range within which zombie will attack the player
-	followRange      = 300.0           // Range within which zombie will follow the player
+	stateSwitchDelay  = 3.0    // Seconds between idle/walk switches while roaming
+	knockbackFriction = 1200.0 // How fast knockback dies down, in pixels per second squared
 )
 
+// zombieClips maps each state to its clip in an archetype's animation file,
+// the dead clip plays once and holds its last frame
+var zombieClips = map[ZombieState]string{
+	ZombieIdle:      "idle",
+	ZombieWalking:   "walk",
+	ZombieAttacking: "attack",
+	ZombieHurt:      "hurt",
+	ZombieDead:      "dead",
+}
 
 var lastIdleSoundTime time.Time // Global cooldown for zombie idle sound
 var isIdleSoundPlaying bool     // Global flag to check if idle sound is currently playing
@@ -33,175 +43,122 @@ var isIdleSoundPlaying bool     // Global flag to check if idle sound is current
 const idleSoundCooldown = 5 * time.Second // Cooldown duration for the idle sound
 const idleSoundProximityRange = 200       // Range within which idle sound plays
 
-
 type Zombie struct {
-	Position        rl.Vector2
-	Speed           rl.Vector2
-	Width, Height   float32
-	Color           rl.Color
-	FacingRight     bool             // Direction the zombie is facing
-	State           ZombieState      // Current animation state
-	FrameCounter    int              // Counter to control frame switch timing
-	CurrentFrame    int              // Current frame index for animation
-	IdleFrames      []rl.Texture2D   // Frames for idle animation
-	WalkFrames      []rl.Texture2D   // Frames for walking animation
-	AttackingFrames []rl.Texture2D   // Frames for attacking animation
-	HurtFrames 	[]rl.Texture2D   // Frames for hurt animation
-	DeadFrames 	[]rl.Texture2D   // Frames for dead animation
-	LastSwitch      time.Time        // Timer for switching states
-	Health          int              // Health points
-    IsAlive         bool             // Whether zombie is alive
+	Position      rl.Vector2
+	Speed         rl.Vector2
+	Width, Height float32
+	Color         rl.Color
+	Type          int                // One of the registered zombie types, like ZombieGirl
+	Archetype     ZombieArchetype    // Stats for the zombie's type
+	FacingRight   bool               // Direction the zombie is facing
+	State         ZombieState        // Current animation state
+	Anim          rendering.Animator // Plays the clip for the current state
+	SwitchTimer   float32            // Seconds since the last idle/walk switch
+	knockback     float32            // Sideways speed from being hit, on top of walking
+	remains       rendering.Emitter  // Mist given off while the death animation plays
+	Health        int                // Health points
+	IsAlive       bool               // Whether zombie is alive
 
 	// Sounds
-    ClawSound       rl.Sound
-    HurtSound       rl.Sound
-    DeathSound      rl.Sound
-	IdleSound       rl.Sound
-	IdleSoundCooldown time.Time        // Cooldown timer for idle sound
+	ClawSound         rl.Sound
+	HurtSound         rl.Sound
+	DeathSound        rl.Sound
+	IdleSound         rl.Sound
+	IdleSoundCooldown time.Time // Cooldown timer for idle sound
 
 }
 
-// Initializing  zombie with default settings and load frames for animations
-func InitZombie(x, y float32, zombieType int) Zombie {
-	spriteSheet := rendering.LoadSpriteSheet("assets/sprites/zombiespritesheet1girl_processed.png")
-	spriteSheet2 := rendering.LoadSpriteSheet("assets/sprites/zombiespritesheet2girl_processed.png")
-
-	// Load sounds for zombie actions
-	clawSound := rl.LoadSound("assets/sounds/zombie_attack.mp3")
-	hurtSound := rl.LoadSound("assets/sounds/zombie_hurt.mp3")
-	deathSound := rl.LoadSound("assets/sounds/zombie_death.mp3")
-	idleSound := rl.LoadSound("assets/sounds/zombie_idle.mp3")
-
-	// animation frames
-	idleFrames := []rl.Rectangle{
-		{X: 233, Y: 67, Width: 55, Height: 99}, //frame 1
-		{X: 385, Y: 67, Width: 55, Height: 99}, //frame 2
-		{X: 540, Y: 67, Width: 56, Height: 99},//frame 3
-		{X: 694, Y: 67, Width: 59, Height: 99},//frame 4
-		{X: 844, Y: 67, Width: 59, Height: 99},//frame 5
-		{X: 1000, Y: 67, Width: 60, Height: 99},//frame 6
-		{X: 1150, Y: 67, Width: 57, Height: 99},//frame 7
-	}
-
-	walkFrames := []rl.Rectangle{
-		{X: 229, Y: 243, Width: 67, Height: 108},//frame 1
-		{X: 380, Y: 244, Width: 72, Height: 107},//frame 2
-		{X: 536, Y: 243, Width: 70, Height: 108},//frame 3
-		{X: 702, Y: 241, Width: 55, Height: 110},//frame 4
-		{X: 837, Y: 241, Width: 73, Height: 110},//frame 5
-		{X: 1000, Y: 241, Width: 66, Height: 110},//frame 6
-		{X: 1150, Y: 241, Width: 68, Height: 110},//frame 7
-		{X: 1308, Y: 241, Width: 64, Height: 110},//frame 8
-	}
-
-	//attacking frames
-	attackingFrames := []rl.Rectangle{
-		{X: 241, Y: 56, Width: 56, Height: 110},//frame 1
-		{X: 387, Y: 54, Width: 51, Height: 112},//frame 2
-		{X: 544, Y: 58, Width: 80, Height: 108},//frame 3
-		{X: 698, Y: 58, Width: 72, Height: 108},//frame 4
-		{X: 837, Y: 59, Width: 71, Height: 107},//frame 5
-	}
-
-	//hurt frames
-	hurtFrames := []rl.Rectangle{
-		{X: 229, Y: 596, Width: 61, Height: 101},
-		{X: 383, Y: 598, Width: 63, Height: 99},
-		{X: 537, Y: 598, Width: 58, Height: 99},
+// Initializing  zombie of the given type at x, y and load its animation clips
+func InitZombie(x, y float32, zombieType int) (Zombie, error) {
+	archetype, err := ZombieArchetypeFor(zombieType)
+	if err != nil {
+		return Zombie{}, err
 	}
-	//Dead frames
-	deadFrames := []rl.Rectangle{
-		{X: 200, Y: 772, Width: 106, Height: 94},
-		{X: 358, Y: 775, Width: 106, Height: 91},
-		{X: 516, Y: 832, Width: 124, Height: 34},
-		{X: 667, Y: 834, Width: 124, Height: 32},
+	animations, err := rendering.LoadAnimations(archetype.Animations)
+	if err != nil {
+		return Zombie{}, err
 	}
 
+	// Load sounds for zombie actions, shared by every zombie
+	clawSound := resources.Sound(archetype.ClawSound)
+	hurtSound := resources.Sound(archetype.HurtSound)
+	deathSound := resources.Sound(archetype.DeathSound)
+	idleSound := resources.Sound(archetype.IdleSound)
 
-
-
-
-	var idleTextures, walkTextures, attackingTextures, hurtTextures, deadTextures []rl.Texture2D
-	for _, frame := range idleFrames {
-		idleTextures = append(idleTextures, spriteSheet.ImageAt(frame, rl.Blank))
-	}
-	for _, frame := range walkFrames {
-		walkTextures = append(walkTextures, spriteSheet.ImageAt(frame, rl.Blank))
-	}
-	for _, frame := range attackingFrames {
-		attackingTextures = append(attackingTextures, spriteSheet2.ImageAt(frame, rl.Blank))
-	}
-	for _, frame := range hurtFrames {
-		hurtTextures = append(hurtTextures, spriteSheet2.ImageAt(frame, rl.Blank))
-	}
-	for _, frame := range deadFrames {
-		deadTextures = append(deadTextures, spriteSheet2.ImageAt(frame, rl.Blank))
-	}
-	
 	return Zombie{
-		Position:        rl.Vector2{X: x, Y: y},
-		Speed:           rl.Vector2{X: 0.05, Y: 0},
-		Width:           113,
-		Height:          113,
-		Color:           rl.Green,
-		FacingRight:     true,
-		State:           ZombieIdle,
-		IdleFrames:      idleTextures,
-		WalkFrames:      walkTextures,
-		AttackingFrames: attackingTextures,
-		HurtFrames:      hurtTextures,
-		LastSwitch:      time.Now(),
-		DeadFrames:      deadTextures,
-		Health:          100, // Set zombie health
-        IsAlive:         true,
+		Position:    rl.Vector2{X: x, Y: y},
+		Speed:       rl.Vector2{X: archetype.RoamSpeed, Y: 0},
+		Width:       archetype.Size,
+		Height:      archetype.Size,
+		Color:       archetype.Tint,
+		Type:        zombieType,
+		Archetype:   archetype,
+		FacingRight: true,
+		State:       ZombieIdle,
+		Anim:        rendering.NewAnimator(animations, archetype.clip(ZombieIdle)),
+		Health:      archetype.Health,
+		IsAlive:     true,
 
 		// Assign loaded sounds
-        ClawSound:       clawSound,
-        HurtSound:       hurtSound,
-        DeathSound:      deathSound,
-		IdleSound:       idleSound, // Assign idle sound
-	}
+		ClawSound:  clawSound,
+		HurtSound:  hurtSound,
+		DeathSound: deathSound,
+		IdleSound:  idleSound, // Assign idle sound
+	}, nil
 }
 
 // TakeDamage reduces the zombie's health by the specified amount, sets it to hurt or dead if health reaches zero
 func (z *Zombie) TakeDamage(damage int) {
-    z.Health -= damage
-    if z.Health <= 0 {
-        z.Health = 0
-        z.setState(ZombieDead)
-        z.IsAlive = false
-        if !rl.IsSoundPlaying(z.DeathSound) {
-            rl.PlaySound(z.DeathSound)
-        }
-    } else {
-        z.setState(ZombieHurt)
-        if !rl.IsSoundPlaying(z.HurtSound) {
-            rl.PlaySound(z.HurtSound)
-        }
-    }
+	z.Health -= damage
+	if z.Health <= 0 {
+		z.Health = 0
+		z.setState(ZombieDead)
+		z.IsAlive = false
+		if !rl.IsSoundPlaying(z.DeathSound) {
+			rl.PlaySound(z.DeathSound)
+		}
+	} else {
+		z.setState(ZombieHurt)
+		if !rl.IsSoundPlaying(z.HurtSound) {
+			rl.PlaySound(z.HurtSound)
+		}
+	}
 }
 
+// Knockback pushes the zombie sideways at speed pixels per second, negative
+// is to the left, and pops it up off the ground a little
+func (z *Zombie) Knockback(speed float32) {
+	z.knockback = speed
+	if z.Speed.Y >= 0 {
+		z.Speed.Y = -abs(speed) / 2
+	}
+}
 
-// Updating zombie behavior to follow and attack player if within range
-func (z *Zombie) Update(worldWidth int, playerPosition rl.Vector2) {
-    if z.State == ZombieDead && z.CurrentFrame >= len(z.DeadFrames)-1 {
-        // Hold the last death frame, marking the zombie as inactive
-        z.IsAlive = false
-        return
-    }
+// Updating zombie behavior to follow and attack player if within range, dt is in seconds
+func (z *Zombie) Update(dt float32, world *physics.World, playerPosition rl.Vector2) {
+	z.Anim.Update(dt)
+	z.remains.Position = z.Position
+	z.remains.Update(dt, Particles)
+
+	if z.DeathFinished() {
+		// Hold the last death frame, marking the zombie as inactive
+		z.IsAlive = false
+		z.move(dt, world, 0) // Corpses still fall
+		return
+	}
 
 	// Calculating distance to player for behavior
 	distanceToPlayer := rl.Vector2Distance(z.Position, playerPosition)
 
-	if z.State == ZombieAttacking && distanceToPlayer <= attackRange {
+	if z.State == ZombieAttacking && distanceToPlayer <= z.Archetype.AttackRange {
 		if !rl.IsSoundPlaying(z.ClawSound) {
-            rl.PlaySound(z.ClawSound)
-        }
+			rl.PlaySound(z.ClawSound)
+		}
 		//stop other sounds
 		rl.StopSound(z.IdleSound)
 		// Reduce player health when attacked
 		if PlayerInstance.Health > 0 {
-			PlayerInstance.Health -= 0.001 // Adjust damage as needed
+			PlayerInstance.Health -= z.Archetype.AttackDamage * float64(dt)
 			if PlayerInstance.Health <= 0 {
 				PlayerInstance.Health = 0
 				if PlayerInstance.IsGameOver() {
@@ -215,133 +172,152 @@ func (z *Zombie) Update(worldWidth int, playerPosition rl.Vector2) {
 	if z.Health <= 0 && z.IsAlive {
 		z.setState(ZombieDead)
 		z.IsAlive = false // Start death animation but zombie is marked inactive
+		z.move(dt, world, 0)
 		return
 	}
-    if z.IsAlive {
-        switch {
-        case distanceToPlayer <= attackRange:
-            z.setState(ZombieAttacking)
-        case distanceToPlayer <= followRange:
-            z.setState(ZombieWalking)
+
+	roaming := false
+	var dx float32 // Horizontal movement this update
+	if z.IsAlive {
+		switch {
+		case distanceToPlayer <= z.Archetype.AttackRange:
+			z.setState(ZombieAttacking)
+		case distanceToPlayer <= z.Archetype.FollowRange:
+			z.setState(ZombieWalking)
 			//print th edistance to player
 			//print the idleSoundProximityRange
 			if distanceToPlayer <= idleSoundProximityRange && !isIdleSoundPlaying && time.Since(lastIdleSoundTime) > idleSoundCooldown {
-                rl.PlaySound(z.IdleSound)
-                lastIdleSoundTime = time.Now() // Reset global cooldown timer
-                isIdleSoundPlaying = true      // Set idle sound as currently playing
-            }
-            if playerPosition.X < z.Position.X {
-                z.FacingRight = false
-                z.Speed.X = -0.02 // Slower speed for zombie movement
-            } else {
-                z.FacingRight = true
-                z.Speed.X = 0.02
-            }
-            z.Position.X += z.Speed.X
-        default:
-            // Randomly switch between idle and walking if outside follow range
-            if time.Since(z.LastSwitch) > stateSwitchDelay {
-                if z.State == ZombieIdle {
-                    z.setState(ZombieWalking)
-                } else {
+				rl.PlaySound(z.IdleSound)
+				lastIdleSoundTime = time.Now() // Reset global cooldown timer
+				isIdleSoundPlaying = true      // Set idle sound as currently playing
+			}
+			if playerPosition.X < z.Position.X {
+				z.FacingRight = false
+				z.Speed.X = -z.Archetype.ChaseSpeed
+			} else {
+				z.FacingRight = true
+				z.Speed.X = z.Archetype.ChaseSpeed
+			}
+			dx = z.Speed.X * dt
+		default:
+			// Randomly switch between idle and walking if outside follow range
+			z.SwitchTimer += dt
+			if z.SwitchTimer > stateSwitchDelay {
+				if z.State == ZombieIdle {
+					z.setState(ZombieWalking)
+				} else {
 					//play idle sound
-					
-                    z.setState(ZombieIdle)
-                }
-                z.LastSwitch = time.Now()
-            }
-
-            // Manages edge flipping in walking state
-            if z.State == ZombieWalking {
-                if z.Position.X < 0 || z.Position.X > float32(worldWidth)-z.Width {
-                    z.Speed.X = -z.Speed.X
-                    z.FacingRight = !z.FacingRight
-                }
-                z.Position.X += z.Speed.X
-            }
-        }
-    }
+
+					z.setState(ZombieIdle)
+				}
+				z.SwitchTimer = 0
+			}
+
+			if z.State == ZombieWalking {
+				// Back to roaming speed after a chase, keeping the current heading
+				roaming = true
+				z.Speed.X = z.Archetype.RoamSpeed
+				if !z.FacingRight {
+					z.Speed.X = -z.Speed.X
+				}
+				dx = z.Speed.X * dt
+			}
+		}
+	}
+
+	// Turn around when roaming into a wall or the edge of the world
+	contacts := z.move(dt, world, dx)
+	if roaming && (contacts.HitLeft || contacts.HitRight) {
+		z.Speed.X = -z.Speed.X
+		z.FacingRight = !z.FacingRight
+	}
 
 	if isIdleSoundPlaying && distanceToPlayer > idleSoundProximityRange {
-        rl.StopSound(z.IdleSound)
-        isIdleSoundPlaying = false
-    }
+		rl.StopSound(z.IdleSound)
+		isIdleSoundPlaying = false
+	}
 }
 
+// move applies gravity and knockback and moves the zombie dx pixels sideways
+// through the world
+func (z *Zombie) move(dt float32, world *physics.World, dx float32) physics.Contacts {
+	z.Speed.Y += gravity * dt
+	if z.knockback != 0 {
+		dx += z.knockback * dt
+		slowdown := knockbackFriction * dt
+		if abs(z.knockback) <= slowdown {
+			z.knockback = 0
+		} else if z.knockback > 0 {
+			z.knockback -= slowdown
+		} else {
+			z.knockback += slowdown
+		}
+	}
+	box, contacts := world.Move(z.Hitbox(), rl.Vector2{X: dx, Y: z.Speed.Y * dt}, false)
+	z.Position = rl.Vector2{X: box.X + box.Width/2, Y: box.Y + box.Height/2}
+	if contacts.OnGround || contacts.HitCeiling {
+		z.Speed.Y = 0
+	}
+	if contacts.HitLeft || contacts.HitRight {
+		z.knockback = 0 // Knocked into a wall
+	}
+	return contacts
+}
+
+func abs(x float32) float32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Hitbox returns the zombie's collision box, Position is the center of the sprite
+func (z *Zombie) Hitbox() rl.Rectangle {
+	return rl.Rectangle{
+		X:      z.Position.X - z.Archetype.HitboxWidth/2,
+		Y:      z.Position.Y - z.Height/2,
+		Width:  z.Archetype.HitboxWidth,
+		Height: z.Height,
+	}
+}
 
 // Helper method to set zombie state and reset frame data
 func (z *Zombie) setState(state ZombieState) {
-    if z.State != state {
-        // Stop sounds as needed
-        if state == ZombieDead {
-            rl.StopSound(z.ClawSound) // Stop attack sound if zombie dies
+	if z.State != state {
+		// Stop sounds as needed
+		if state == ZombieDead {
+			rl.StopSound(z.ClawSound) // Stop attack sound if zombie dies
 			rl.StopSound(z.IdleSound) // Stop idle sound if zombie dies
-        }
-		
-        
-        z.State = state
-        z.CurrentFrame = 0
-        z.FrameCounter = 0
-    }
+			Particles.Burst(bloodBurst, z.Position, rl.Vector2{Y: -1})
+			z.remains = rendering.Emitter{Def: deathMist, Position: z.Position, Direction: rl.Vector2{Y: -1}, Active: true}
+			PlayerInstance.Score += z.Archetype.Score // Scored on the kill, so a save made while it falls keeps it
+		}
+
+		z.State = state
+		z.Anim.Play(z.Archetype.clip(state))
+	}
 }
 func (z *Zombie) UnloadSounds() {
-    rl.UnloadSound(z.ClawSound)
-    rl.UnloadSound(z.HurtSound)
-    rl.UnloadSound(z.DeathSound)
-	rl.UnloadSound(z.IdleSound) 
+	resources.ReleaseSound(z.ClawSound)
+	resources.ReleaseSound(z.HurtSound)
+	resources.ReleaseSound(z.DeathSound)
+	resources.ReleaseSound(z.IdleSound)
+}
+
+// Unload releases the sprite sheets and sounds used by the zombie
+func (z *Zombie) Unload() {
+	if z.Anim.Set != nil {
+		z.Anim.Set.Unload()
+	}
+	z.UnloadSounds()
+}
+
+// DeathFinished reports whether the zombie's death animation has played out
+func (z *Zombie) DeathFinished() bool {
+	return z.State == ZombieDead && z.Anim.Finished()
 }
 
 // Drawing zombie based on the current frame and state
 func (z *Zombie) Draw() {
-    var frames []rl.Texture2D
-    switch z.State {
-    case ZombieWalking:
-        frames = z.WalkFrames
-    case ZombieAttacking:
-        frames = z.AttackingFrames
-    case ZombieHurt:
-        frames = z.HurtFrames
-    case ZombieDead:
-        frames = z.DeadFrames
-    default:
-        frames = z.IdleFrames
-    }
-
-    if len(frames) > 0 {
-        frame := frames[z.CurrentFrame]
-        z.FrameCounter++
-
-        // Differentiate frame timing for the death state
-        if z.State == ZombieDead {
-            if z.FrameCounter >= frameDelay / 20 { // Slower death frame rate
-                if z.CurrentFrame < len(frames)-1 {
-                    z.CurrentFrame++
-                }
-                z.FrameCounter = 0
-            }
-        } else {
-            // Standard frame delay for all other states
-            if z.FrameCounter >= frameDelay / 10 {
-                z.CurrentFrame = (z.CurrentFrame + 1) % len(frames)
-                z.FrameCounter = 0
-            }
-        }
-
-        // Source rectangle setup for animation and flipping
-        sourceRect := rl.Rectangle{X: 0, Y: 0, Width: float32(frame.Width), Height: float32(frame.Height)}
-        if !z.FacingRight {
-            sourceRect.Width = -sourceRect.Width
-        }
-
-        // Drawing the current frame
-        destinationRect := rl.Rectangle{
-            X:      z.Position.X,
-            Y:      z.Position.Y,
-            Width:  z.Width,
-            Height: z.Height,
-        }
-        if frame.ID != 0 {
-            rl.DrawTexturePro(frame, sourceRect, destinationRect, rl.Vector2{X: z.Width / 2, Y: z.Height / 2}, 0, z.Color)
-        }
-    }
+	z.Anim.Draw(z.Position, rl.Vector2{X: z.Width, Y: z.Height}, !z.FacingRight, z.Color)
 }
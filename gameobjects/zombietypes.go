@@ -0,0 +1,195 @@
+package gameobjects
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Zombie types, the values a level's zombie_spawn "zombieType" property takes
+const (
+	ZombieBoy = iota
+	ZombieGirl
+	ZombieRunner
+	ZombieTank
+)
+
+// ZombieArchetype describes a kind of zombie: how it looks, sounds and fights
+type ZombieArchetype struct {
+	Name         string
+	Animations   string                 // Animation file with idle, walk, attack, hurt and dead clips
+	Clips        map[ZombieState]string // Clips to use instead of the defaults in zombieClips
+	Tint         rl.Color
+	Size         float32 // Width and height the sprite is drawn at
+	HitboxWidth  float32 // Width of the collision box, narrower than the sprite
+	Health       int
+	RoamSpeed    float32   // Wandering speed in pixels per second
+	ChaseSpeed   float32   // Speed while following the player, in pixels per second
+	AttackDamage float64   // Player health lost per second of attacking
+	AttackRange  float32   // Range within which the zombie attacks the player
+	FollowRange  float32   // Range within which the zombie follows the player
+	Loot         LootTable // Dropped when the zombie dies
+	Score        int       // Points for killing one
+
+	// Sounds
+	ClawSound  string
+	HurtSound  string
+	DeathSound string
+	IdleSound  string
+}
+
+// zombieArchetypes holds every zombie type that can be spawned
+var zombieArchetypes = map[int]ZombieArchetype{
+	ZombieBoy: {
+		Name:         "boy",
+		Animations:   "assets/animations/zombie_boy.json",
+		Tint:         rl.Green,
+		Size:         113,
+		HitboxWidth:  50,
+		Health:       120,
+		RoamSpeed:    35,
+		ChaseSpeed:   55,
+		AttackDamage: 12,
+		AttackRange:  50,
+		FollowRange:  300,
+		Score:        100,
+		Loot: LootTable{
+			Guaranteed: []LootEntry{{Item: "coin", Min: 1, Max: 3}},
+			Rolls:      1,
+			Entries: []LootEntry{
+				{Weight: 50},
+				{Item: "ammo", Weight: 35, Min: 10, Max: 30},
+				{Item: "bandage", Weight: 15, Min: 1, Max: 1},
+			},
+		},
+	},
+	ZombieGirl: {
+		Name:         "girl",
+		Animations:   "assets/animations/zombie_girl.json",
+		Tint:         rl.Green,
+		Size:         113,
+		HitboxWidth:  50,
+		Health:       100,
+		RoamSpeed:    40,
+		ChaseSpeed:   60,
+		AttackDamage: 10,
+		AttackRange:  50,
+		FollowRange:  300,
+		Score:        100,
+		Loot: LootTable{
+			Guaranteed: []LootEntry{{Item: "coin", Min: 1, Max: 2}},
+			Rolls:      1,
+			Entries: []LootEntry{
+				{Weight: 55},
+				{Item: "ammo", Weight: 30, Min: 10, Max: 20},
+				{Item: "health_pack", Weight: 15, Min: 1, Max: 1},
+			},
+		},
+	},
+	ZombieRunner: {
+		Name:         "runner",
+		Animations:   "assets/animations/zombie_boy.json",
+		Clips:        map[ZombieState]string{ZombieWalking: "run"}, // Sprints instead of shambling
+		Tint:         rl.NewColor(255, 200, 120, 255),
+		Size:         105,
+		HitboxWidth:  45,
+		Health:       60,
+		RoamSpeed:    70,
+		ChaseSpeed:   170,
+		AttackDamage: 8,
+		AttackRange:  50,
+		FollowRange:  450,
+		Score:        150,
+		Loot: LootTable{
+			Guaranteed: []LootEntry{{Item: "coin", Min: 1, Max: 1}},
+			Rolls:      1,
+			Entries: []LootEntry{
+				{Weight: 60},
+				{Item: "adrenaline", Weight: 25, Min: 1, Max: 1},
+				{Item: "ammo", Weight: 15, Min: 5, Max: 15},
+			},
+		},
+	},
+	ZombieTank: {
+		Name:         "tank",
+		Animations:   "assets/animations/zombie_girl.json",
+		Tint:         rl.DarkGreen,
+		Size:         150,
+		HitboxWidth:  70,
+		Health:       350,
+		RoamSpeed:    20,
+		ChaseSpeed:   35,
+		AttackDamage: 25,
+		AttackRange:  70,
+		FollowRange:  250,
+		Score:        400,
+		Loot: LootTable{
+			Guaranteed: []LootEntry{
+				{Item: "coin", Min: 5, Max: 10},
+				{Item: "ammo", Min: 30, Max: 60},
+			},
+			Rolls: 2,
+			Entries: []LootEntry{
+				{Weight: 30},
+				{Item: "health_pack", Weight: 40, Min: 1, Max: 1},
+				{Item: "med_kit", Weight: 15, Min: 1, Max: 1},
+				{Item: "adrenaline", Weight: 15, Min: 1, Max: 1},
+			},
+		},
+	},
+}
+
+// Every archetype shares the same sounds unless it sets its own. Their loot
+// is checked the way RegisterZombieArchetype checks added ones, so a typo in
+// an item ID fails as the game starts.
+func init() {
+	for zombieType, archetype := range zombieArchetypes {
+		if err := archetype.Loot.Check(); err != nil {
+			panic(fmt.Sprintf("zombie type %d: %v", zombieType, err))
+		}
+		zombieArchetypes[zombieType] = archetype.withDefaultSounds()
+	}
+}
+
+func (a ZombieArchetype) withDefaultSounds() ZombieArchetype {
+	if a.ClawSound == "" {
+		a.ClawSound = "assets/sounds/zombie_attack.mp3"
+	}
+	if a.HurtSound == "" {
+		a.HurtSound = "assets/sounds/zombie_hurt.mp3"
+	}
+	if a.DeathSound == "" {
+		a.DeathSound = "assets/sounds/zombie_death.mp3"
+	}
+	if a.IdleSound == "" {
+		a.IdleSound = "assets/sounds/zombie_idle.mp3"
+	}
+	return a
+}
+
+// RegisterZombieArchetype adds a zombie type, or replaces an existing one.
+// Its loot table may only drop registered items.
+func RegisterZombieArchetype(zombieType int, archetype ZombieArchetype) error {
+	if err := archetype.Loot.Check(); err != nil {
+		return fmt.Errorf("zombie type %d: %w", zombieType, err)
+	}
+	zombieArchetypes[zombieType] = archetype.withDefaultSounds()
+	return nil
+}
+
+// ZombieArchetypeFor returns the archetype registered for zombieType
+func ZombieArchetypeFor(zombieType int) (ZombieArchetype, error) {
+	archetype, ok := zombieArchetypes[zombieType]
+	if !ok {
+		return ZombieArchetype{}, fmt.Errorf("unknown zombie type %d", zombieType)
+	}
+	return archetype, nil
+}
+
+// clip returns the name of the clip the archetype plays in state
+func (a *ZombieArchetype) clip(state ZombieState) string {
+	if name, ok := a.Clips[state]; ok {
+		return name
+	}
+	return zombieClips[state]
+}
@@ -0,0 +1,107 @@
+// Package hud lays out the heads-up display: widgets pinned to an anchor on
+// the screen, like the top-left corner, at an offset from it.
+//
+// Layouts are made for a reference screen size and scaled to the real one, so
+// the HUD keeps its proportions at any resolution. Offsets and sizes are in
+// reference pixels. Game code never draws the HUD itself, it hands values to
+// the widgets (Bar.Set, Label.Text, Prompt.Show...) and the HUD draws them.
+package hud
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Anchor is the point of the screen a widget is pinned to
+type Anchor int
+
+const (
+	TopLeft Anchor = iota
+	Top
+	TopRight
+	Left
+	Center
+	Right
+	BottomLeft
+	Bottom
+	BottomRight
+)
+
+// point returns where the anchor is across and down the screen, from 0 to 1
+func (a Anchor) point() rl.Vector2 {
+	return rl.Vector2{X: float32(a%3) / 2, Y: float32(a/3) / 2}
+}
+
+// Widget is something the HUD can place on screen
+type Widget interface {
+	Size() rl.Vector2                      // In reference pixels
+	Update(dt float32)                     // Animates the widget, dt is in seconds
+	Draw(corner rl.Vector2, scale float32) // Draws with the top-left corner at corner, in screen pixels
+}
+
+// Element is a widget placed on the HUD
+type Element struct {
+	Widget
+	Anchor Anchor
+	Offset rl.Vector2 // Reference pixels in from the anchored edges, right and down from a centered anchor
+	Hidden bool
+}
+
+// HUD is a set of widgets drawn over the game
+type HUD struct {
+	Reference rl.Vector2 // Screen size the layout is made for
+	elements  []*Element
+}
+
+// New makes an empty HUD laid out for a referenceWidth by referenceHeight screen
+func New(referenceWidth, referenceHeight float32) *HUD {
+	return &HUD{Reference: rl.Vector2{X: referenceWidth, Y: referenceHeight}}
+}
+
+// Add places widget at offset from anchor, later widgets are drawn over earlier ones
+func (h *HUD) Add(widget Widget, anchor Anchor, offset rl.Vector2) *Element {
+	element := &Element{Widget: widget, Anchor: anchor, Offset: offset}
+	h.elements = append(h.elements, element)
+	return element
+}
+
+// Scale returns how many screen pixels a reference pixel is. The smaller of
+// the two axes wins, so the HUD always fits.
+func (h *HUD) Scale() float32 {
+	return min(float32(rl.GetScreenWidth())/h.Reference.X, float32(rl.GetScreenHeight())/h.Reference.Y)
+}
+
+// Update animates every widget, hidden ones included, over dt seconds
+func (h *HUD) Update(dt float32) {
+	for _, element := range h.elements {
+		element.Update(dt)
+	}
+}
+
+// Draw draws every widget that isn't hidden, in screen space
+func (h *HUD) Draw() {
+	scale := h.Scale()
+	screen := rl.Vector2{X: float32(rl.GetScreenWidth()), Y: float32(rl.GetScreenHeight())}
+	for _, element := range h.elements {
+		if element.Hidden {
+			continue
+		}
+		element.Draw(element.corner(screen, scale), scale)
+	}
+}
+
+// corner works out where the element's top-left corner goes on a screen of
+// the given size
+func (e *Element) corner(screen rl.Vector2, scale float32) rl.Vector2 {
+	point := e.Anchor.point()
+	size := rl.Vector2Scale(e.Size(), scale)
+	inward := func(at float32) float32 {
+		if at == 1 {
+			return -1 // From the right or bottom edge, offsets go back toward the middle
+		}
+		return 1
+	}
+	return rl.Vector2{
+		X: point.X*screen.X + inward(point.X)*e.Offset.X*scale - point.X*size.X,
+		Y: point.Y*screen.Y + inward(point.Y)*e.Offset.Y*scale - point.Y*size.Y,
+	}
+}
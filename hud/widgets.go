@@ -0,0 +1,250 @@
+package hud
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	defaultFontSize = 10
+	lagHold         = 0.5  // Seconds the lost part of a bar lingers after the last drop
+	lagDrain        = 0.6  // Share of a full bar the lost part drains by per second
+	promptFade      = 0.25 // Seconds a prompt takes to fade in or out
+	promptPadding   = 6
+)
+
+// Bar fills up to a value out of a maximum, like health. When the value
+// drops, the part that was lost lingers in LagColor until the drops stop for
+// a moment, then drains away, so the size of a hit is easy to read.
+type Bar struct {
+	Width, Height float32
+	Color         rl.Color
+	LagColor      rl.Color
+	Background    rl.Color
+	Label         string // Drawn on the bar
+	FontSize      float32
+
+	value, max float32
+	lagged     float32 // Where the lost part reaches up to
+	hold       float32 // Seconds until the lost part starts draining
+	set        bool    // Set has been called, so drops can be told apart from the first value
+}
+
+// NewBar makes a bar of the given size filled with color
+func NewBar(width, height float32, color rl.Color) *Bar {
+	return &Bar{
+		Width:      width,
+		Height:     height,
+		Color:      color,
+		LagColor:   rl.Orange,
+		Background: rl.DarkGray,
+		FontSize:   defaultFontSize,
+	}
+}
+
+// Set changes the bar's value and maximum. The first value shows straight
+// away; after that drops leave a trail behind and rises fill at once.
+func (b *Bar) Set(value, max float32) {
+	value = clampFloat(value, 0, max)
+	if b.set && value < b.value {
+		b.hold = lagHold
+	}
+	if !b.set || value > b.lagged {
+		b.lagged = value
+	}
+	b.value, b.max, b.set = value, max, true
+}
+
+func (b *Bar) Size() rl.Vector2 {
+	return rl.Vector2{X: b.Width, Y: b.Height}
+}
+
+func (b *Bar) Update(dt float32) {
+	if b.hold > 0 {
+		b.hold -= dt
+		return
+	}
+	b.lagged = max(b.lagged-lagDrain*b.max*dt, b.value)
+}
+
+func (b *Bar) Draw(corner rl.Vector2, scale float32) {
+	width, height := b.Width*scale, b.Height*scale
+	rl.DrawRectangleRec(rl.Rectangle{X: corner.X, Y: corner.Y, Width: width, Height: height}, b.Background)
+	if b.max > 0 {
+		rl.DrawRectangleRec(rl.Rectangle{X: corner.X, Y: corner.Y, Width: width * b.lagged / b.max, Height: height}, b.LagColor)
+		rl.DrawRectangleRec(rl.Rectangle{X: corner.X, Y: corner.Y, Width: width * b.value / b.max, Height: height}, b.Color)
+	}
+	if b.Label != "" {
+		drawText(b.Label, rl.Vector2{X: corner.X + 10*scale, Y: corner.Y + (height-b.FontSize*scale)/2}, b.FontSize, scale, rl.White)
+	}
+}
+
+// Label is a line of text, with an optional thin progress bar over it for
+// things like reloads
+type Label struct {
+	Text          string
+	Color         rl.Color
+	FontSize      float32
+	Progress      float32 // From 0 to 1, the bar is only drawn above 0
+	ProgressWidth float32
+}
+
+// NewLabel makes a label in color at the default font size
+func NewLabel(color rl.Color) *Label {
+	return &Label{Color: color, FontSize: defaultFontSize, ProgressWidth: 200}
+}
+
+func (l *Label) Size() rl.Vector2 {
+	return rl.Vector2{X: measureText(l.Text, l.FontSize), Y: l.FontSize}
+}
+
+func (l *Label) Update(dt float32) {}
+
+func (l *Label) Draw(corner rl.Vector2, scale float32) {
+	if l.Progress > 0 {
+		rl.DrawRectangleRec(rl.Rectangle{X: corner.X, Y: corner.Y - 6*scale, Width: l.ProgressWidth * min(l.Progress, 1) * scale, Height: 4 * scale}, rl.LightGray)
+	}
+	drawText(l.Text, corner, l.FontSize, scale, l.Color)
+}
+
+// Icon shows a texture in a framed box with a caption beside it, like the
+// held item
+type Icon struct {
+	Texture  rl.Texture2D // The box is left empty when this isn't loaded
+	Caption  string
+	Box      float32 // Width and height of the box
+	Color    rl.Color
+	FontSize float32
+}
+
+// NewIcon makes an empty icon with a box of the given size
+func NewIcon(box float32) *Icon {
+	return &Icon{Box: box, Color: rl.White, FontSize: defaultFontSize}
+}
+
+func (i *Icon) Size() rl.Vector2 {
+	width := i.Box
+	if i.Caption != "" {
+		width += 6 + measureText(i.Caption, i.FontSize)
+	}
+	return rl.Vector2{X: width, Y: i.Box}
+}
+
+func (i *Icon) Update(dt float32) {}
+
+func (i *Icon) Draw(corner rl.Vector2, scale float32) {
+	box := rl.Rectangle{X: corner.X, Y: corner.Y, Width: i.Box * scale, Height: i.Box * scale}
+	rl.DrawRectangleRec(box, rl.Fade(rl.Black, 0.5))
+	rl.DrawRectangleLinesEx(box, 1, rl.Gray)
+
+	if i.Texture.ID != 0 {
+		// Fit the texture inside the box, keeping its shape
+		padding := 4 * scale
+		fit := min((box.Width-2*padding)/float32(i.Texture.Width), (box.Height-2*padding)/float32(i.Texture.Height))
+		width, height := float32(i.Texture.Width)*fit, float32(i.Texture.Height)*fit
+		source := rl.Rectangle{Width: float32(i.Texture.Width), Height: float32(i.Texture.Height)}
+		dest := rl.Rectangle{X: box.X + (box.Width-width)/2, Y: box.Y + (box.Height-height)/2, Width: width, Height: height}
+		rl.DrawTexturePro(i.Texture, source, dest, rl.Vector2{}, 0, rl.White)
+	}
+	if i.Caption != "" {
+		drawText(i.Caption, rl.Vector2{X: box.X + box.Width + 6*scale, Y: box.Y + (box.Height-i.FontSize*scale)/2}, i.FontSize, scale, i.Color)
+	}
+}
+
+// Line is one line of a List. Progress, from 0 to 1, underlines part of it,
+// like the time a buff has left.
+type Line struct {
+	Text     string
+	Color    rl.Color
+	Progress float32
+}
+
+// List stacks lines of text, like the active buffs
+type List struct {
+	Lines    []Line
+	FontSize float32
+	Spacing  float32 // Reference pixels from the top of one line to the next
+}
+
+// NewList makes an empty list at the default font size
+func NewList() *List {
+	return &List{FontSize: defaultFontSize, Spacing: defaultFontSize + 4}
+}
+
+func (l *List) Size() rl.Vector2 {
+	var width float32
+	for _, line := range l.Lines {
+		width = max(width, measureText(line.Text, l.FontSize))
+	}
+	return rl.Vector2{X: width, Y: l.Spacing * float32(len(l.Lines))}
+}
+
+func (l *List) Update(dt float32) {}
+
+func (l *List) Draw(corner rl.Vector2, scale float32) {
+	for i, line := range l.Lines {
+		position := rl.Vector2{X: corner.X, Y: corner.Y + float32(i)*l.Spacing*scale}
+		drawText(line.Text, position, l.FontSize, scale, line.Color)
+		if line.Progress > 0 {
+			width := measureText(line.Text, l.FontSize) * min(line.Progress, 1) * scale
+			under := position.Y + (l.FontSize+1)*scale
+			rl.DrawLineEx(rl.Vector2{X: position.X, Y: under}, rl.Vector2{X: position.X + width, Y: under}, scale, rl.Fade(line.Color, 0.6))
+		}
+	}
+}
+
+// Prompt is a hint like "Press E to pick up", shown for as long as the game
+// keeps asking for it every update and fading out once it stops
+type Prompt struct {
+	Color    rl.Color
+	FontSize float32
+	text     string
+	asked    bool    // Show was called since the last update
+	shown    float32 // How faded in it is, from 0 to 1
+}
+
+// NewPrompt makes a prompt in color at the default font size
+func NewPrompt(color rl.Color) *Prompt {
+	return &Prompt{Color: color, FontSize: defaultFontSize}
+}
+
+// Show asks for the prompt to show text, call it every update while it applies
+func (p *Prompt) Show(text string) {
+	p.text = text
+	p.asked = true
+}
+
+func (p *Prompt) Size() rl.Vector2 {
+	return rl.Vector2{X: measureText(p.text, p.FontSize) + 2*promptPadding, Y: p.FontSize + 2*promptPadding}
+}
+
+func (p *Prompt) Update(dt float32) {
+	if p.asked {
+		p.shown = min(p.shown+dt/promptFade, 1)
+	} else {
+		p.shown = max(p.shown-dt/promptFade, 0)
+	}
+	p.asked = false
+}
+
+func (p *Prompt) Draw(corner rl.Vector2, scale float32) {
+	if p.shown == 0 {
+		return
+	}
+	size := rl.Vector2Scale(p.Size(), scale)
+	rl.DrawRectangleRec(rl.Rectangle{X: corner.X, Y: corner.Y, Width: size.X, Height: size.Y}, rl.Fade(rl.Black, 0.5*p.shown))
+	drawText(p.text, rl.Vector2{X: corner.X + promptPadding*scale, Y: corner.Y + promptPadding*scale}, p.FontSize, scale, rl.Fade(p.Color, p.shown))
+}
+
+// drawText draws text at a reference font size, scaled to the screen
+func drawText(text string, position rl.Vector2, fontSize, scale float32, color rl.Color) {
+	rl.DrawText(text, int32(position.X), int32(position.Y), int32(fontSize*scale), color)
+}
+
+// measureText returns how wide text is in reference pixels
+func measureText(text string, fontSize float32) float32 {
+	return float32(rl.MeasureText(text, int32(fontSize)))
+}
+
+func clampFloat(value, low, high float32) float32 {
+	return max(low, min(value, high))
+}
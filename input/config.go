@@ -0,0 +1,88 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// configFile is the bindings file inside the user's config directory
+const configFile = "platformer-game/bindings.json"
+
+// ConfigPath returns where the user's bindings are kept
+func ConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFile), nil
+}
+
+// Load replaces the bindings with the ones saved at path. Actions the file
+// doesn't mention keep their defaults, and a missing file isn't an error.
+func Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var saved map[string][]Binding
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	loaded := Defaults()
+	for _, a := range Actions {
+		if bound, ok := saved[a.ID()]; ok {
+			loaded[a] = bound
+			delete(saved, a.ID())
+		}
+	}
+	for id := range saved {
+		log.Printf("%s: ignoring bindings for unknown action %q", path, id)
+	}
+	bindings = loaded
+	return nil
+}
+
+// Save writes the current bindings to path
+func Save(path string) error {
+	saved := make(map[string][]Binding, len(bindings))
+	for a, bound := range bindings {
+		saved[a.ID()] = bound
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadUserConfig loads the user's bindings, logging instead of failing since
+// the defaults are always there to fall back on
+func LoadUserConfig() {
+	path, err := ConfigPath()
+	if err == nil {
+		err = Load(path)
+	}
+	if err != nil {
+		log.Printf("Using default key bindings: %v", err)
+	}
+}
+
+// SaveUserConfig saves the current bindings as the user's
+func SaveUserConfig() error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	return Save(path)
+}
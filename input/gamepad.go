@@ -0,0 +1,132 @@
+package input
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	maxGamepads   = 4    // Gamepad slots checked for a plugged in pad
+	AxisThreshold = 0.3  // How far a stick or trigger goes, 0 to 1, before its binding counts as held
+	RunThreshold  = 0.85 // Pushing the movement stick further than this runs instead of walking
+	captureTravel = 0.6  // How far a stick has to be pushed to be bound in the controls menu
+)
+
+var (
+	pad      int32 = -1 // Gamepad being read, -1 when none is plugged in
+	padName  string
+	axes     [rl.GamepadAxisRightTrigger + 1]float32 // Every axis this frame, triggers scaled to 0 to 1
+	lastAxes [rl.GamepadAxisRightTrigger + 1]float32 // Every axis last frame, for presses and releases
+	padEvent *GamepadEvent                           // Plugged in or out this frame
+)
+
+// GamepadEvent is the gamepad being plugged in or out
+type GamepadEvent struct {
+	Name      string
+	Connected bool
+}
+
+// updateGamepad picks up pads plugged in or out since last frame and reads the
+// sticks and triggers. The first pad found is used until it's unplugged.
+func updateGamepad() {
+	padEvent = nil
+	if pad >= 0 && !rl.IsGamepadAvailable(pad) {
+		padEvent = &GamepadEvent{Name: padName, Connected: false}
+		pad = -1
+	}
+	if pad < 0 {
+		for i := int32(0); i < maxGamepads; i++ {
+			if rl.IsGamepadAvailable(i) {
+				pad = i
+				padName = rl.GetGamepadName(i)
+				padEvent = &GamepadEvent{Name: padName, Connected: true}
+				break
+			}
+		}
+	}
+
+	lastAxes = axes
+	for axis := range axes {
+		axes[axis] = readAxis(int32(axis))
+	}
+}
+
+func readAxis(axis int32) float32 {
+	if pad < 0 {
+		return 0
+	}
+	value := rl.GetGamepadAxisMovement(pad, axis)
+	if axis == rl.GamepadAxisLeftTrigger || axis == rl.GamepadAxisRightTrigger {
+		value = (value + 1) / 2 // Triggers rest at -1
+	}
+	return value
+}
+
+// GamepadChanged reports the gamepad being plugged in or out this frame
+func GamepadChanged() (GamepadEvent, bool) {
+	if padEvent == nil {
+		return GamepadEvent{}, false
+	}
+	return *padEvent, true
+}
+
+// GamepadConnected reports whether a gamepad is plugged in
+func GamepadConnected() bool {
+	return pad >= 0
+}
+
+// PadPressed reports whether a gamepad button went down this frame, for menus
+// that don't go through bindings
+func PadPressed(button int32) bool {
+	return pad >= 0 && rl.IsGamepadButtonPressed(pad, button)
+}
+
+// AimStick returns the direction the right stick is pushed, once it's pushed
+// past AxisThreshold
+func AimStick() (rl.Vector2, bool) {
+	stick := rl.Vector2{X: axes[rl.GamepadAxisRightX], Y: axes[rl.GamepadAxisRightY]}
+	if rl.Vector2Length(stick) < AxisThreshold {
+		return rl.Vector2{}, false
+	}
+	return rl.Vector2Normalize(stick), true
+}
+
+// travel returns how far an axis binding is pushed its way, 0 to 1
+func travel(values *[rl.GamepadAxisRightTrigger + 1]float32, b Binding) float32 {
+	if b.Code < 0 || int(b.Code) >= len(values) {
+		return 0
+	}
+	return max(0, values[b.Code]*float32(b.Dir))
+}
+
+// Tilt returns how far the action's stick and trigger bindings are pushed, 0
+// to 1. Keys and buttons don't count, so it tells a nudge from a full push.
+func Tilt(a Action) float32 {
+	tilt := float32(0)
+	for _, b := range bindings[a] {
+		if b.Device == PadAxis {
+			tilt = max(tilt, travel(&axes, b))
+		}
+	}
+	return tilt
+}
+
+// captureGamepad returns the first gamepad button pressed or stick pushed this frame
+func captureGamepad() (Binding, bool) {
+	if pad < 0 {
+		return Binding{}, false
+	}
+	for button := int32(rl.GamepadButtonLeftFaceUp); button <= rl.GamepadButtonRightThumb; button++ {
+		if rl.IsGamepadButtonPressed(pad, button) {
+			return PadButton(button), true
+		}
+	}
+	for axis := range axes {
+		for _, dir := range []int32{1, -1} {
+			b := PadStick(int32(axis), dir)
+			if travel(&axes, b) >= captureTravel && travel(&lastAxes, b) < captureTravel {
+				return b, true
+			}
+		}
+	}
+	return Binding{}, false
+}
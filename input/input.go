@@ -0,0 +1,296 @@
+// Package input maps named game actions, like Jump or Fire, to the keys, mouse
+// buttons and gamepad buttons bound to them.
+//
+// Gameplay code asks about actions rather than raw keys, so bindings can be
+// changed from the controls menu or the user's config file without touching
+// it. Presses are also latched here: Update runs once per rendered frame and
+// remembers every action pressed since, until the fixed-step simulation
+// consumes it, so a press isn't dropped or repeated when a frame runs zero or
+// several updates.
+package input
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Action is something the player can do, bound to one or more inputs
+type Action int
+
+const (
+	MoveLeft Action = iota
+	MoveRight
+	Run
+	Jump
+	Crouch
+	Fire
+	Reload
+	Interact
+	UseItem
+	ToggleInventory
+	InventoryLeft
+	InventoryRight
+	InventoryUp
+	InventoryDown
+	ToggleMap
+	QuickSave
+	QuickLoad
+	actionCount
+)
+
+// Actions lists every action in the order the controls menu shows them
+var Actions = func() []Action {
+	actions := make([]Action, actionCount)
+	for i := range actions {
+		actions[i] = Action(i)
+	}
+	return actions
+}()
+
+// actionInfo names each action, ID in the config file and Label on screen
+var actionInfo = [actionCount]struct{ ID, Label string }{
+	MoveLeft:        {"move_left", "Move left"},
+	MoveRight:       {"move_right", "Move right"},
+	Run:             {"run", "Run"},
+	Jump:            {"jump", "Jump"},
+	Crouch:          {"crouch", "Crouch"},
+	Fire:            {"fire", "Shoot / Swing"},
+	Reload:          {"reload", "Reload"},
+	Interact:        {"interact", "Pick up item"},
+	UseItem:         {"use_item", "Use selected item"},
+	ToggleInventory: {"toggle_inventory", "Inventory"},
+	InventoryLeft:   {"inventory_left", "Select slot left"},
+	InventoryRight:  {"inventory_right", "Select slot right"},
+	InventoryUp:     {"inventory_up", "Select slot up"},
+	InventoryDown:   {"inventory_down", "Select slot down"},
+	ToggleMap:       {"toggle_map", "Full-screen map"},
+	QuickSave:       {"quick_save", "Quicksave"},
+	QuickLoad:       {"quick_load", "Quickload"},
+}
+
+// ID returns the name the action is saved under in the config file
+func (a Action) ID() string {
+	return actionInfo[a].ID
+}
+
+// Label returns the name shown for the action in menus
+func (a Action) Label() string {
+	return actionInfo[a].Label
+}
+
+// Device is the kind of input a binding reads
+type Device int
+
+const (
+	Keyboard Device = iota
+	Mouse
+	Gamepad
+	PadAxis // A stick or trigger pushed one way, see AxisThreshold
+)
+
+// Binding is a single key, mouse button, gamepad button or stick direction
+type Binding struct {
+	Device Device
+	Code   int32 // rl.Key*, rl.MouseButton*, rl.GamepadButton* or rl.GamepadAxis* depending on Device
+	Dir    int32 // Which way a PadAxis is pushed, 1 or -1
+}
+
+// Key, MouseButton, PadButton and PadStick make bindings for each device
+func Key(key int32) Binding                     { return Binding{Device: Keyboard, Code: key} }
+func MouseButton(button rl.MouseButton) Binding { return Binding{Device: Mouse, Code: int32(button)} }
+func PadButton(button int32) Binding            { return Binding{Device: Gamepad, Code: button} }
+func PadStick(axis, dir int32) Binding          { return Binding{Device: PadAxis, Code: axis, Dir: dir} }
+
+// onGamepad reports whether the binding is on the gamepad
+func (b Binding) onGamepad() bool {
+	return b.Device == Gamepad || b.Device == PadAxis
+}
+
+func (b Binding) down() bool {
+	switch b.Device {
+	case Keyboard:
+		return rl.IsKeyDown(b.Code)
+	case Mouse:
+		return rl.IsMouseButtonDown(rl.MouseButton(b.Code))
+	case Gamepad:
+		return pad >= 0 && rl.IsGamepadButtonDown(pad, b.Code)
+	case PadAxis:
+		return travel(&axes, b) >= AxisThreshold
+	}
+	return false
+}
+
+func (b Binding) pressed() bool {
+	switch b.Device {
+	case Keyboard:
+		return rl.IsKeyPressed(b.Code)
+	case Mouse:
+		return rl.IsMouseButtonPressed(rl.MouseButton(b.Code))
+	case Gamepad:
+		return pad >= 0 && rl.IsGamepadButtonPressed(pad, b.Code)
+	case PadAxis:
+		return travel(&axes, b) >= AxisThreshold && travel(&lastAxes, b) < AxisThreshold
+	}
+	return false
+}
+
+func (b Binding) released() bool {
+	switch b.Device {
+	case Keyboard:
+		return rl.IsKeyReleased(b.Code)
+	case Mouse:
+		return rl.IsMouseButtonReleased(rl.MouseButton(b.Code))
+	case Gamepad:
+		return pad >= 0 && rl.IsGamepadButtonReleased(pad, b.Code)
+	case PadAxis:
+		return travel(&axes, b) < AxisThreshold && travel(&lastAxes, b) >= AxisThreshold
+	}
+	return false
+}
+
+// Bindings holds the inputs bound to each action, any of them triggers it
+type Bindings map[Action][]Binding
+
+// Defaults returns the bindings the game ships with
+func Defaults() Bindings {
+	return Bindings{
+		MoveLeft:        {Key(rl.KeyA), PadStick(rl.GamepadAxisLeftX, -1)},
+		MoveRight:       {Key(rl.KeyD), PadStick(rl.GamepadAxisLeftX, 1)},
+		Run:             {Key(rl.KeyLeftShift), PadButton(rl.GamepadButtonLeftThumb)},
+		Jump:            {Key(rl.KeySpace), PadButton(rl.GamepadButtonRightFaceDown)},
+		Crouch:          {Key(rl.KeyLeftControl), PadButton(rl.GamepadButtonRightFaceRight)},
+		Fire:            {MouseButton(rl.MouseButtonLeft), PadButton(rl.GamepadButtonRightTrigger2)},
+		Reload:          {Key(rl.KeyR), PadButton(rl.GamepadButtonRightTrigger1)},
+		Interact:        {Key(rl.KeyE), PadButton(rl.GamepadButtonRightFaceLeft)},
+		UseItem:         {Key(rl.KeyQ), PadButton(rl.GamepadButtonLeftTrigger1)},
+		ToggleInventory: {Key(rl.KeyI), PadButton(rl.GamepadButtonRightFaceUp)},
+		InventoryLeft:   {Key(rl.KeyLeft), PadButton(rl.GamepadButtonLeftFaceLeft)},
+		InventoryRight:  {Key(rl.KeyRight), PadButton(rl.GamepadButtonLeftFaceRight)},
+		InventoryUp:     {Key(rl.KeyUp), PadButton(rl.GamepadButtonLeftFaceUp)},
+		InventoryDown:   {Key(rl.KeyDown), PadButton(rl.GamepadButtonLeftFaceDown)},
+		ToggleMap:       {Key(rl.KeyM), PadButton(rl.GamepadButtonMiddleLeft)},
+		QuickSave:       {Key(rl.KeyF5)},
+		QuickLoad:       {Key(rl.KeyF9)},
+	}
+}
+
+var (
+	bindings = Defaults()
+	latched  [actionCount]bool // Pressed since last consumed
+)
+
+// Bound returns the inputs bound to an action
+func Bound(a Action) []Binding {
+	return bindings[a]
+}
+
+// Bind makes b the action's main binding, replacing the one it had. Other
+// actions lose b so one input never triggers two of them.
+func Bind(a Action, b Binding) {
+	unbind(b)
+	// The main binding is the first one on the same kind of device, so
+	// rebinding a key leaves the gamepad button alone
+	for i, existing := range bindings[a] {
+		if existing.onGamepad() == b.onGamepad() {
+			bindings[a][i] = b
+			return
+		}
+	}
+	bindings[a] = append([]Binding{b}, bindings[a]...)
+}
+
+// Reset puts an action back to its default bindings, taking them from any
+// other action they were rebound to
+func Reset(a Action) {
+	defaults := Defaults()[a]
+	for _, b := range defaults {
+		unbind(b)
+	}
+	bindings[a] = defaults
+}
+
+// unbind takes b off every action
+func unbind(b Binding) {
+	for a, bound := range bindings {
+		kept := bound[:0]
+		for _, existing := range bound {
+			if existing != b {
+				kept = append(kept, existing)
+			}
+		}
+		bindings[a] = kept
+	}
+}
+
+// Down reports whether any input bound to the action is held
+func Down(a Action) bool {
+	for _, b := range bindings[a] {
+		if b.down() {
+			return true
+		}
+	}
+	return false
+}
+
+// Pressed reports whether an input bound to the action went down this frame
+func Pressed(a Action) bool {
+	for _, b := range bindings[a] {
+		if b.pressed() {
+			return true
+		}
+	}
+	return false
+}
+
+// Released reports whether an input bound to the action went up this frame
+func Released(a Action) bool {
+	for _, b := range bindings[a] {
+		if b.released() {
+			return true
+		}
+	}
+	return false
+}
+
+// Update reads the gamepad and latches this frame's presses, call it once per
+// rendered frame
+func Update() {
+	updateGamepad()
+	for _, a := range Actions {
+		if Pressed(a) {
+			latched[a] = true
+		}
+	}
+}
+
+// Latched reports whether the action was pressed since it was last consumed,
+// without consuming it
+func Latched(a Action) bool {
+	return latched[a]
+}
+
+// Consume reports whether the action was pressed since it was last consumed
+func Consume(a Action) bool {
+	pressed := latched[a]
+	latched[a] = false
+	return pressed
+}
+
+// Flush forgets every latched press, so presses don't carry over between
+// scenes that stop consuming them
+func Flush() {
+	latched = [actionCount]bool{}
+}
+
+// Capture returns the first key, mouse button, gamepad button or stick pressed
+// this frame, for binding it to an action
+func Capture() (Binding, bool) {
+	if key := rl.GetKeyPressed(); key != 0 {
+		return Key(key), true
+	}
+	for button := rl.MouseButtonLeft; button <= rl.MouseButtonBack; button++ {
+		if rl.IsMouseButtonPressed(button) {
+			return MouseButton(button), true
+		}
+	}
+	return captureGamepad()
+}
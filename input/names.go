@@ -0,0 +1,187 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// inputName pairs an input code with the name it's saved under and the label
+// shown in menus
+type inputName struct {
+	Code  int32
+	ID    string
+	Label string
+}
+
+// keyNames covers every key worth binding, letters and digits are added below
+var keyNames = []inputName{
+	{rl.KeySpace, "space", "Space"},
+	{rl.KeyEnter, "enter", "Enter"},
+	{rl.KeyTab, "tab", "Tab"},
+	{rl.KeyBackspace, "backspace", "Backspace"},
+	{rl.KeyInsert, "insert", "Insert"},
+	{rl.KeyDelete, "delete", "Delete"},
+	{rl.KeyHome, "home", "Home"},
+	{rl.KeyEnd, "end", "End"},
+	{rl.KeyPageUp, "page_up", "Page Up"},
+	{rl.KeyPageDown, "page_down", "Page Down"},
+	{rl.KeyLeft, "left", "Left"},
+	{rl.KeyRight, "right", "Right"},
+	{rl.KeyUp, "up", "Up"},
+	{rl.KeyDown, "down", "Down"},
+	{rl.KeyLeftShift, "left_shift", "Shift"},
+	{rl.KeyRightShift, "right_shift", "Right Shift"},
+	{rl.KeyLeftControl, "left_control", "Control"},
+	{rl.KeyRightControl, "right_control", "Right Control"},
+	{rl.KeyLeftAlt, "left_alt", "Alt"},
+	{rl.KeyRightAlt, "right_alt", "Right Alt"},
+	{rl.KeyCapsLock, "caps_lock", "Caps Lock"},
+	{rl.KeyApostrophe, "apostrophe", "'"},
+	{rl.KeyComma, "comma", ","},
+	{rl.KeyMinus, "minus", "-"},
+	{rl.KeyPeriod, "period", "."},
+	{rl.KeySlash, "slash", "/"},
+	{rl.KeySemicolon, "semicolon", ";"},
+	{rl.KeyEqual, "equal", "="},
+	{rl.KeyLeftBracket, "left_bracket", "["},
+	{rl.KeyBackSlash, "backslash", "\\"},
+	{rl.KeyRightBracket, "right_bracket", "]"},
+	{rl.KeyGrave, "grave", "`"},
+}
+
+func init() {
+	for key := int32(rl.KeyA); key <= rl.KeyZ; key++ {
+		letter := string(rune(key))
+		keyNames = append(keyNames, inputName{key, strings.ToLower(letter), letter})
+	}
+	for key := int32(rl.KeyZero); key <= rl.KeyNine; key++ {
+		digit := string(rune(key))
+		keyNames = append(keyNames, inputName{key, digit, digit})
+	}
+	for n := int32(1); n <= 12; n++ {
+		name := fmt.Sprintf("F%d", n)
+		keyNames = append(keyNames, inputName{rl.KeyF1 + n - 1, strings.ToLower(name), name})
+	}
+	for n := int32(0); n <= 9; n++ {
+		keyNames = append(keyNames, inputName{rl.KeyKp0 + n, fmt.Sprintf("kp_%d", n), fmt.Sprintf("Keypad %d", n)})
+	}
+}
+
+var mouseNames = []inputName{
+	{int32(rl.MouseButtonLeft), "left", "Left Mouse"},
+	{int32(rl.MouseButtonRight), "right", "Right Mouse"},
+	{int32(rl.MouseButtonMiddle), "middle", "Middle Mouse"},
+	{int32(rl.MouseButtonSide), "side", "Mouse 4"},
+	{int32(rl.MouseButtonExtra), "extra", "Mouse 5"},
+	{int32(rl.MouseButtonForward), "forward", "Mouse Forward"},
+	{int32(rl.MouseButtonBack), "back", "Mouse Back"},
+}
+
+// padNames use Xbox labels, the face buttons are the same positions on any pad
+var padNames = []inputName{
+	{rl.GamepadButtonLeftFaceUp, "dpad_up", "D-Pad Up"},
+	{rl.GamepadButtonLeftFaceRight, "dpad_right", "D-Pad Right"},
+	{rl.GamepadButtonLeftFaceDown, "dpad_down", "D-Pad Down"},
+	{rl.GamepadButtonLeftFaceLeft, "dpad_left", "D-Pad Left"},
+	{rl.GamepadButtonRightFaceUp, "y", "Pad Y"},
+	{rl.GamepadButtonRightFaceRight, "b", "Pad B"},
+	{rl.GamepadButtonRightFaceDown, "a", "Pad A"},
+	{rl.GamepadButtonRightFaceLeft, "x", "Pad X"},
+	{rl.GamepadButtonLeftTrigger1, "lb", "Pad LB"},
+	{rl.GamepadButtonLeftTrigger2, "lt", "Pad LT"},
+	{rl.GamepadButtonRightTrigger1, "rb", "Pad RB"},
+	{rl.GamepadButtonRightTrigger2, "rt", "Pad RT"},
+	{rl.GamepadButtonMiddleLeft, "back", "Pad Back"},
+	{rl.GamepadButtonMiddle, "guide", "Pad Guide"},
+	{rl.GamepadButtonMiddleRight, "start", "Pad Start"},
+	{rl.GamepadButtonLeftThumb, "left_stick", "Pad Left Stick"},
+	{rl.GamepadButtonRightThumb, "right_stick", "Pad Right Stick"},
+}
+
+// axisNames covers each way a stick or trigger can be pushed, Code is the axis
+var axisNames = []struct {
+	inputName
+	Dir int32
+}{
+	{inputName{rl.GamepadAxisLeftX, "left_x-", "Left Stick Left"}, -1},
+	{inputName{rl.GamepadAxisLeftX, "left_x+", "Left Stick Right"}, 1},
+	{inputName{rl.GamepadAxisLeftY, "left_y-", "Left Stick Up"}, -1},
+	{inputName{rl.GamepadAxisLeftY, "left_y+", "Left Stick Down"}, 1},
+	{inputName{rl.GamepadAxisRightX, "right_x-", "Right Stick Left"}, -1},
+	{inputName{rl.GamepadAxisRightX, "right_x+", "Right Stick Right"}, 1},
+	{inputName{rl.GamepadAxisRightY, "right_y-", "Right Stick Up"}, -1},
+	{inputName{rl.GamepadAxisRightY, "right_y+", "Right Stick Down"}, 1},
+	{inputName{rl.GamepadAxisLeftTrigger, "lt", "Pad LT (analog)"}, 1},
+	{inputName{rl.GamepadAxisRightTrigger, "rt", "Pad RT (analog)"}, 1},
+}
+
+// devicePrefixes start a binding's name in the config file, like "key:space"
+var devicePrefixes = map[Device]string{Keyboard: "key", Mouse: "mouse", Gamepad: "pad", PadAxis: "axis"}
+
+// namesFor returns the name table of a device, as bindings
+func namesFor(device Device) map[Binding]inputName {
+	names := map[Binding]inputName{}
+	switch device {
+	case Keyboard:
+		for _, name := range keyNames {
+			names[Key(name.Code)] = name
+		}
+	case Mouse:
+		for _, name := range mouseNames {
+			names[MouseButton(rl.MouseButton(name.Code))] = name
+		}
+	case Gamepad:
+		for _, name := range padNames {
+			names[PadButton(name.Code)] = name
+		}
+	case PadAxis:
+		for _, name := range axisNames {
+			names[PadStick(name.Code, name.Dir)] = name.inputName
+		}
+	}
+	return names
+}
+
+// String returns the binding's label, like "Space" or "Pad A"
+func (b Binding) String() string {
+	if name, ok := namesFor(b.Device)[b]; ok {
+		return name.Label
+	}
+	return fmt.Sprintf("%s %d", devicePrefixes[b.Device], b.Code)
+}
+
+// MarshalText saves a binding by name, so the config file stays readable
+func (b Binding) MarshalText() ([]byte, error) {
+	if name, ok := namesFor(b.Device)[b]; ok {
+		return []byte(devicePrefixes[b.Device] + ":" + name.ID), nil
+	}
+	return []byte(fmt.Sprintf("%s:%d", devicePrefixes[b.Device], b.Code)), nil // Keys without a name are saved by code
+}
+
+// UnmarshalText reads a binding saved by MarshalText
+func (b *Binding) UnmarshalText(text []byte) error {
+	prefix, id, ok := strings.Cut(string(text), ":")
+	if !ok {
+		return fmt.Errorf("binding %q should look like key:space, mouse:left, pad:a or axis:left_x+", text)
+	}
+	for device, devicePrefix := range devicePrefixes {
+		if devicePrefix != prefix {
+			continue
+		}
+		for binding, name := range namesFor(device) {
+			if name.ID == id {
+				*b = binding
+				return nil
+			}
+		}
+		var code int32
+		if _, err := fmt.Sscan(id, &code); err == nil && device != PadAxis {
+			*b = Binding{Device: device, Code: code}
+			return nil
+		}
+		return fmt.Errorf("unknown %s %q", prefix, id)
+	}
+	return fmt.Errorf("unknown input device %q", prefix)
+}
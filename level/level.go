@@ -0,0 +1,437 @@
+// Package level loads maps made in the Tiled editor (https://www.mapeditor.org)
+// saved as JSON (.tmj/.json) or XML (.tmx).
+//
+// Tile layers and image layers are drawn in map order. A tile layer named
+// "collision", or with a bool property "collision" set to true, marks every
+// non-empty tile as solid; hide it in Tiled if it shouldn't be drawn. A tile
+// layer named "oneway", or with a bool property "oneway", marks its tiles as
+// platforms that can be jumped through from below. Object layers place things
+// in the world by object class:
+//
+//	player_spawn  where the player starts (a point)
+//	zombie_spawn  where a zombie starts, int property "zombieType" (0 boy,
+//	              1 girl, 2 runner, 3 tank; see gameobjects.ZombieArchetype)
+//	item          a world item, named after the object, string property
+//	              "itemType" (weapon, healthpack, consumable, ammo, currency
+//	              or other), file property "texture", and optional string
+//	              property "itemId" (defaults to the name) and int property
+//	              "count"
+//	collision     a solid rectangle, for shapes that don't fit the tile grid,
+//	              with bool property "oneway" for a jump-through platform
+//	exit          a rectangle the player has to reach to finish the level
+//	              once every wave is cleared
+//
+// Layers follow Tiled's parallax factors (multiplied by their groups'), moving
+// slower or faster than the map as the view moves away from the map's parallax
+// origin. Image layers can repeat across the view horizontally and vertically,
+// and float properties "scrollx" and "scrolly" make one drift by on its own at
+// that many pixels per second, for clouds.
+//
+// The map itself can set a string property "id", a file property "waves"
+// naming the wave file zombies are spawned from, and a float property "zoom"
+// for how far the camera is zoomed in (1 when unset).
+package level
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"platformer-game/physics"
+	"platformer-game/resources"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Tiled stores flip flags in the top bits of every tile GID
+const (
+	flippedHorizontally = 0x80000000
+	flippedVertically   = 0x40000000
+	flippedDiagonally   = 0x20000000
+	rotatedHexagonal120 = 0x10000000
+	gidMask             = ^uint32(flippedHorizontally | flippedVertically | flippedDiagonally | rotatedHexagonal120)
+)
+
+// Level is a loaded map, ready to be drawn and queried by the game
+type Level struct {
+	ID                    string // Map property "id", or the file name without extension
+	Path                  string
+	Waves                 string  // Map property "waves", empty to use the game's default waves
+	Zoom                  float32 // Map property "zoom", the camera's zoom on this level
+	Width, Height         int     // Size in pixels
+	Columns, Rows         int     // Size in tiles
+	TileWidth, TileHeight int
+
+	PlayerSpawn    rl.Vector2
+	HasPlayerSpawn bool
+	ZombieSpawns   []ZombieSpawn
+	Items          []ItemPlacement
+	Exits          []rl.Rectangle // Without any, clearing the waves finishes the level
+	Collision      *physics.World // Collision tiles and rectangles characters move through
+
+	layers         []drawLayer // Tile and image layers in drawing order
+	tilesets       []*tileset
+	parallaxOrigin rl.Vector2 // Where the view's center is when parallax layers sit at their offsets
+}
+
+// ZombieSpawn is where a zombie starts out in the level
+type ZombieSpawn struct {
+	Position   rl.Vector2
+	ZombieType int
+}
+
+// ItemPlacement is a world item placed in the level
+type ItemPlacement struct {
+	Position rl.Vector2
+	ID       string // Item ID, which items stack with each other
+	Name     string
+	ItemType string // "weapon", "healthpack", "consumable", "ammo", "currency" or "other"
+	Texture  string // Path to the item's texture
+	Count    int    // Units in the pile
+}
+
+type drawLayer struct {
+	name     string
+	offset   rl.Vector2
+	tint     rl.Color
+	parallax rl.Vector2   // How far the layer moves as the view moves, 1 with the map and 0 not at all
+	gids     []uint32     // Set for tile layers
+	columns  int          // Width of a tile layer in tiles
+	isImage  bool         // An image layer rather than a tile layer
+	image    rl.Texture2D // Set for image layers, ID 0 if the picture didn't load
+	repeatX  bool         // Image repeated across the view
+	repeatY  bool
+	scroll   rl.Vector2 // Pixels per second an image layer drifts by on its own
+	drift    rl.Vector2 // How far it has drifted so far
+}
+
+type tileset struct {
+	firstGID              uint32
+	texture               rl.Texture2D
+	tileWidth, tileHeight int
+	columns, tileCount    int
+	margin, spacing       int
+}
+
+// Load reads a Tiled map and the textures it uses. Call Unload when done.
+func Load(path string) (*Level, error) {
+	var (
+		m   *tiledMap
+		err error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tmx":
+		m, err = readTMXMap(path)
+	default:
+		m, err = readJSONMap(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.Infinite {
+		return nil, fmt.Errorf("%s: infinite maps are not supported", path)
+	}
+	if m.TileWidth <= 0 || m.TileHeight <= 0 {
+		return nil, fmt.Errorf("%s: invalid tile size %dx%d", path, m.TileWidth, m.TileHeight)
+	}
+
+	base := filepath.Base(path)
+	lvl := &Level{
+		ID:         stringProperty(m.Properties, "id", strings.TrimSuffix(base, filepath.Ext(base))),
+		Path:       path,
+		Zoom:       float32(floatProperty(m.Properties, "zoom", 1)),
+		Columns:    m.Width,
+		Rows:       m.Height,
+		TileWidth:  m.TileWidth,
+		TileHeight: m.TileHeight,
+		Width:      m.Width * m.TileWidth,
+		Height:     m.Height * m.TileHeight,
+		Collision:  physics.NewWorld(m.Width, m.Height, float32(m.TileWidth), float32(m.TileHeight)),
+
+		parallaxOrigin: rl.Vector2{X: m.ParallaxOriginX, Y: m.ParallaxOriginY},
+	}
+	if lvl.Zoom <= 0 {
+		return nil, fmt.Errorf("%s: zoom must be above 0, got %g", path, lvl.Zoom)
+	}
+
+	for _, ts := range m.Tilesets {
+		lvl.tilesets = append(lvl.tilesets, &tileset{
+			firstGID:   ts.FirstGID,
+			texture:    resources.Texture(resolvePath(ts.dir, ts.Image)),
+			tileWidth:  ts.TileWidth,
+			tileHeight: ts.TileHeight,
+			columns:    ts.Columns,
+			tileCount:  ts.TileCount,
+			margin:     ts.Margin,
+			spacing:    ts.Spacing,
+		})
+	}
+
+	if waves := stringProperty(m.Properties, "waves", ""); waves != "" {
+		lvl.Waves = resolvePath(filepath.Dir(path), waves)
+	}
+
+	if err := lvl.addLayers(m.Layers, filepath.Dir(path), rl.Vector2{}, rl.Vector2{X: 1, Y: 1}); err != nil {
+		lvl.Unload()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return lvl, nil
+}
+
+// addLayers walks the map's layers, flattening groups into their children.
+// A group's offset adds to its children's and its parallax multiplies theirs.
+func (l *Level) addLayers(layers []tiledLayer, dir string, offset, parallax rl.Vector2) error {
+	for _, layer := range layers {
+		layerOffset := rl.Vector2Add(offset, rl.Vector2{X: layer.OffsetX, Y: layer.OffsetY})
+		parallaxX, parallaxY := layer.parallax()
+		layerParallax := rl.Vector2{X: parallax.X * parallaxX, Y: parallax.Y * parallaxY}
+		switch layer.Type {
+		case "group":
+			if err := l.addLayers(layer.Layers, dir, layerOffset, layerParallax); err != nil {
+				return err
+			}
+
+		case "tilelayer":
+			if len(layer.gids) != l.Columns*l.Rows {
+				return fmt.Errorf("layer %q has %d tiles, expected %d", layer.Name, len(layer.gids), l.Columns*l.Rows)
+			}
+			switch {
+			case layer.Name == "collision" || boolProperty(layer.Properties, "collision", false):
+				l.markTiles(layer.gids, physics.Solid)
+			case layer.Name == "oneway" || boolProperty(layer.Properties, "oneway", false):
+				l.markTiles(layer.gids, physics.OneWay)
+			}
+			if layer.Visible {
+				l.layers = append(l.layers, drawLayer{
+					name:     layer.Name,
+					offset:   layerOffset,
+					tint:     rl.Fade(rl.White, layer.Opacity),
+					parallax: layerParallax,
+					gids:     layer.gids,
+					columns:  l.Columns,
+				})
+			}
+
+		case "imagelayer":
+			if layer.Visible && layer.Image != "" {
+				l.layers = append(l.layers, drawLayer{
+					name:     layer.Name,
+					offset:   layerOffset,
+					tint:     rl.Fade(rl.White, layer.Opacity),
+					parallax: layerParallax,
+					isImage:  true,
+					image:    resources.Texture(resolvePath(dir, layer.Image)),
+					repeatX:  layer.RepeatX,
+					repeatY:  layer.RepeatY,
+					scroll: rl.Vector2{
+						X: float32(floatProperty(layer.Properties, "scrollx", 0)),
+						Y: float32(floatProperty(layer.Properties, "scrolly", 0)),
+					},
+				})
+			}
+
+		case "objectgroup":
+			for _, obj := range layer.Objects {
+				if err := l.addObject(obj, dir, layerOffset); err != nil {
+					return fmt.Errorf("layer %q: %w", layer.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addObject places one object from an object layer according to its class
+func (l *Level) addObject(obj tiledObject, dir string, offset rl.Vector2) error {
+	position := rl.Vector2{X: obj.X + offset.X, Y: obj.Y + offset.Y}
+	switch obj.class() {
+	case "player_spawn":
+		l.PlayerSpawn = position
+		l.HasPlayerSpawn = true
+	case "zombie_spawn":
+		l.ZombieSpawns = append(l.ZombieSpawns, ZombieSpawn{
+			Position:   position,
+			ZombieType: intProperty(obj.Properties, "zombieType", 0),
+		})
+	case "item":
+		texture := stringProperty(obj.Properties, "texture", "")
+		if texture == "" {
+			return fmt.Errorf("item %q (object %d) has no texture property", obj.Name, obj.ID)
+		}
+		if prop, _ := property(obj.Properties, "texture"); prop.Type == "file" {
+			texture = resolvePath(dir, texture) // File properties are relative to the map
+		}
+		l.Items = append(l.Items, ItemPlacement{
+			Position: position,
+			ID:       stringProperty(obj.Properties, "itemId", obj.Name),
+			Name:     obj.Name,
+			ItemType: stringProperty(obj.Properties, "itemType", "other"),
+			Texture:  texture,
+			Count:    max(intProperty(obj.Properties, "count", 1), 1),
+		})
+	case "collision":
+		l.Collision.Platforms = append(l.Collision.Platforms, physics.Platform{
+			Rect:   rl.Rectangle{X: position.X, Y: position.Y, Width: obj.Width, Height: obj.Height},
+			OneWay: boolProperty(obj.Properties, "oneway", false),
+		})
+	case "exit":
+		l.Exits = append(l.Exits, rl.Rectangle{X: position.X, Y: position.Y, Width: obj.Width, Height: obj.Height})
+	}
+	return nil
+}
+
+// markTiles gives every non-empty tile of a collision layer the given kind,
+// solid wins where a one-way layer and a solid layer overlap
+func (l *Level) markTiles(gids []uint32, kind physics.TileKind) {
+	for i, gid := range gids {
+		if gid&gidMask == 0 || l.Collision.Tiles[i] == physics.Solid {
+			continue
+		}
+		l.Collision.Tiles[i] = kind
+	}
+}
+
+// Unload releases the textures used by the level's tilesets and image layers
+func (l *Level) Unload() {
+	for _, ts := range l.tilesets {
+		resources.ReleaseTexture(ts.texture)
+	}
+	for _, layer := range l.layers {
+		if layer.image.ID != 0 {
+			resources.ReleaseTexture(layer.image)
+		}
+	}
+	l.tilesets = nil
+	l.layers = nil
+}
+
+// Update drifts the auto-scrolling image layers along by dt seconds
+func (l *Level) Update(dt float32) {
+	for i := range l.layers {
+		layer := &l.layers[i]
+		if layer.scroll == (rl.Vector2{}) {
+			continue
+		}
+		layer.drift = rl.Vector2Add(layer.drift, rl.Vector2Scale(layer.scroll, dt))
+		// A repeating image looks the same a whole image further on, so keep the drift small
+		if layer.repeatX {
+			layer.drift.X = wrap(layer.drift.X, float32(layer.image.Width))
+		}
+		if layer.repeatY {
+			layer.drift.Y = wrap(layer.drift.Y, float32(layer.image.Height))
+		}
+	}
+}
+
+// Draw draws every visible tile and image layer, tiles outside view are
+// skipped. Parallax layers are moved for where view is.
+func (l *Level) Draw(view rl.Rectangle) {
+	center := rl.Vector2{X: view.X + view.Width/2, Y: view.Y + view.Height/2}
+	for _, layer := range l.layers {
+		// A parallax layer falls behind the map by the share of the view's movement it doesn't follow
+		shift := rl.Vector2Subtract(center, l.parallaxOrigin)
+		layer.offset.X += shift.X*(1-layer.parallax.X) + layer.drift.X
+		layer.offset.Y += shift.Y*(1-layer.parallax.Y) + layer.drift.Y
+		if layer.isImage {
+			if layer.image.ID != 0 {
+				drawImageLayer(layer, view) // A picture that failed to load was logged and leaves the layer empty
+			}
+			continue
+		}
+		l.drawTileLayer(layer, view)
+	}
+}
+
+// drawImageLayer draws an image layer, repeated to fill the view along the
+// axes it repeats on
+func drawImageLayer(layer drawLayer, view rl.Rectangle) {
+	width, height := float32(layer.image.Width), float32(layer.image.Height)
+	first, last := layer.offset, layer.offset
+	if layer.repeatX {
+		first.X = layer.offset.X + float32(math.Floor(float64((view.X-layer.offset.X)/width)))*width
+		last.X = view.X + view.Width
+	}
+	if layer.repeatY {
+		first.Y = layer.offset.Y + float32(math.Floor(float64((view.Y-layer.offset.Y)/height)))*height
+		last.Y = view.Y + view.Height
+	}
+
+	for y := first.Y; y <= last.Y; y += height {
+		for x := first.X; x <= last.X; x += width {
+			bounds := rl.Rectangle{X: x, Y: y, Width: width, Height: height}
+			if rl.CheckCollisionRecs(bounds, view) {
+				rl.DrawTextureV(layer.image, rl.Vector2{X: x, Y: y}, layer.tint)
+			}
+		}
+	}
+}
+
+// wrap takes whole sizes off value, keeping its sign
+func wrap(value, size float32) float32 {
+	if size <= 0 {
+		return value
+	}
+	return float32(math.Mod(float64(value), float64(size)))
+}
+
+func (l *Level) drawTileLayer(layer drawLayer, view rl.Rectangle) {
+	// Only walk the tiles that overlap the view
+	firstCol := max(int((view.X-layer.offset.X)/float32(l.TileWidth)), 0)
+	firstRow := max(int((view.Y-layer.offset.Y)/float32(l.TileHeight)), 0)
+	lastCol := min(int((view.X+view.Width-layer.offset.X)/float32(l.TileWidth))+1, l.Columns-1)
+	lastRow := min(int((view.Y+view.Height-layer.offset.Y)/float32(l.TileHeight))+1, l.Rows-1)
+
+	for row := firstRow; row <= lastRow; row++ {
+		for col := firstCol; col <= lastCol; col++ {
+			gid := layer.gids[row*layer.columns+col]
+			if gid&gidMask == 0 {
+				continue
+			}
+			ts := l.tilesetFor(gid & gidMask)
+			if ts == nil {
+				continue
+			}
+
+			source := ts.sourceRect(gid&gidMask - ts.firstGID)
+			if gid&flippedHorizontally != 0 {
+				source.Width = -source.Width
+			}
+			if gid&flippedVertically != 0 {
+				source.Height = -source.Height
+			}
+			// Tiles bigger than the map grid are anchored to the bottom-left of their cell
+			position := rl.Vector2{
+				X: layer.offset.X + float32(col*l.TileWidth),
+				Y: layer.offset.Y + float32((row+1)*l.TileHeight-ts.tileHeight),
+			}
+			rl.DrawTextureRec(ts.texture, source, position, layer.tint)
+		}
+	}
+}
+
+// tilesetFor finds the tileset a GID belongs to, tilesets are sorted by firstGID
+func (l *Level) tilesetFor(gid uint32) *tileset {
+	for i := len(l.tilesets) - 1; i >= 0; i-- {
+		if gid >= l.tilesets[i].firstGID {
+			return l.tilesets[i]
+		}
+	}
+	return nil
+}
+
+// sourceRect returns where a tile sits in the tileset image
+func (ts *tileset) sourceRect(localID uint32) rl.Rectangle {
+	columns := ts.columns
+	if columns <= 0 {
+		columns = 1
+	}
+	col := int(localID) % columns
+	row := int(localID) / columns
+	return rl.Rectangle{
+		X:      float32(ts.margin + col*(ts.tileWidth+ts.spacing)),
+		Y:      float32(ts.margin + row*(ts.tileHeight+ts.spacing)),
+		Width:  float32(ts.tileWidth),
+		Height: float32(ts.tileHeight),
+	}
+}
@@ -0,0 +1,299 @@
+package level
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The tiled* types mirror the parts of Tiled's map format the game uses.
+// JSON maps decode straight into them and TMX maps are converted into them,
+// so everything after parsing only deals with one representation.
+
+type tiledMap struct {
+	Width           int             `json:"width"`  // Map width in tiles
+	Height          int             `json:"height"` // Map height in tiles
+	TileWidth       int             `json:"tilewidth"`
+	TileHeight      int             `json:"tileheight"`
+	Infinite        bool            `json:"infinite"`
+	ParallaxOriginX float32         `json:"parallaxoriginx"` // Where the view's center is when parallax layers sit at their offsets
+	ParallaxOriginY float32         `json:"parallaxoriginy"`
+	Layers          []tiledLayer    `json:"layers"`
+	Tilesets        []tiledTileset  `json:"tilesets"`
+	Properties      []tiledProperty `json:"properties"`
+}
+
+type tiledLayer struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type"` // "tilelayer", "objectgroup", "imagelayer" or "group"
+	Visible     bool            `json:"visible"`
+	Opacity     float32         `json:"opacity"`
+	OffsetX     float32         `json:"offsetx"`
+	OffsetY     float32         `json:"offsety"`
+	ParallaxX   *float32        `json:"parallaxx"` // Left out when 1, see parallax
+	ParallaxY   *float32        `json:"parallaxy"`
+	RepeatX     bool            `json:"repeatx"` // Image layers only
+	RepeatY     bool            `json:"repeaty"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Data        json.RawMessage `json:"data"`        // Array of GIDs, or a base64 string
+	Encoding    string          `json:"encoding"`    // "csv" or "base64"
+	Compression string          `json:"compression"` // "", "zlib" or "gzip"
+	Objects     []tiledObject   `json:"objects"`
+	Image       string          `json:"image"`
+	Layers      []tiledLayer    `json:"layers"` // Children of a group layer
+	Properties  []tiledProperty `json:"properties"`
+
+	gids []uint32 // Decoded tile data
+}
+
+type tiledObject struct {
+	ID         int             `json:"id"`
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Class      string          `json:"class"` // Tiled 1.9+ renamed "type" to "class"
+	X          float32         `json:"x"`
+	Y          float32         `json:"y"`
+	Width      float32         `json:"width"`
+	Height     float32         `json:"height"`
+	Point      bool            `json:"point"`
+	GID        uint32          `json:"gid"`
+	Properties []tiledProperty `json:"properties"`
+}
+
+type tiledTileset struct {
+	FirstGID    uint32 `json:"firstgid"`
+	Source      string `json:"source"` // External tileset file, relative to the map
+	Name        string `json:"name"`
+	Image       string `json:"image"`
+	ImageWidth  int    `json:"imagewidth"`
+	ImageHeight int    `json:"imageheight"`
+	TileWidth   int    `json:"tilewidth"`
+	TileHeight  int    `json:"tileheight"`
+	TileCount   int    `json:"tilecount"`
+	Columns     int    `json:"columns"`
+	Margin      int    `json:"margin"`
+	Spacing     int    `json:"spacing"`
+
+	dir string // Directory image paths are relative to
+}
+
+type tiledProperty struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"` // "string", "int", "float", "bool", "color" or "file"
+	Value any    `json:"value"`
+}
+
+// parallax returns how fast the layer moves with the view on each axis, 1
+// (moving with the map) when Tiled left it out
+func (l *tiledLayer) parallax() (x, y float32) {
+	x, y = 1, 1
+	if l.ParallaxX != nil {
+		x = *l.ParallaxX
+	}
+	if l.ParallaxY != nil {
+		y = *l.ParallaxY
+	}
+	return x, y
+}
+
+// class returns the object's class, whichever Tiled version wrote it
+func (o *tiledObject) class() string {
+	if o.Class != "" {
+		return o.Class
+	}
+	return o.Type
+}
+
+// readJSONMap parses a map exported from Tiled as JSON (.tmj or .json)
+func readJSONMap(path string) (*tiledMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m tiledMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for i := range m.Tilesets {
+		if err := m.Tilesets[i].resolve(dir); err != nil {
+			return nil, err
+		}
+	}
+	if err := decodeLayers(m.Layers); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// resolve loads an external tileset if the map only references one
+func (ts *tiledTileset) resolve(dir string) error {
+	ts.dir = dir
+	if ts.Source == "" {
+		return nil
+	}
+
+	path := resolvePath(dir, ts.Source)
+	firstGID := ts.FirstGID
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tsx":
+		external, err := readTSX(path)
+		if err != nil {
+			return err
+		}
+		*ts = *external
+	default:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, ts); err != nil {
+			return fmt.Errorf("parsing tileset %s: %w", path, err)
+		}
+	}
+	ts.FirstGID = firstGID
+	ts.dir = filepath.Dir(path)
+	return nil
+}
+
+// decodeLayers turns the raw tile data of every tile layer into GIDs
+func decodeLayers(layers []tiledLayer) error {
+	for i := range layers {
+		layer := &layers[i]
+		switch layer.Type {
+		case "group":
+			if err := decodeLayers(layer.Layers); err != nil {
+				return err
+			}
+		case "tilelayer":
+			gids, err := decodeJSONTileData(layer)
+			if err != nil {
+				return fmt.Errorf("layer %q: %w", layer.Name, err)
+			}
+			layer.gids = gids
+		}
+	}
+	return nil
+}
+
+func decodeJSONTileData(layer *tiledLayer) ([]uint32, error) {
+	if len(layer.Data) == 0 {
+		return nil, fmt.Errorf("no tile data, infinite maps are not supported")
+	}
+	if layer.Encoding != "base64" {
+		var gids []uint32
+		if err := json.Unmarshal(layer.Data, &gids); err != nil {
+			return nil, err
+		}
+		return gids, nil
+	}
+
+	var encoded string
+	if err := json.Unmarshal(layer.Data, &encoded); err != nil {
+		return nil, err
+	}
+	return decodeBase64TileData(encoded, layer.Compression)
+}
+
+// decodeBase64TileData decodes Tiled's base64 encoding, a little-endian
+// uint32 per tile, optionally compressed
+func decodeBase64TileData(encoded, compression string) ([]uint32, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader = bytes.NewReader(raw)
+	switch compression {
+	case "":
+	case "zlib":
+		if reader, err = zlib.NewReader(reader); err != nil {
+			return nil, err
+		}
+	case "gzip":
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tile data compression %q", compression)
+	}
+
+	raw, err = io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw)%4 != 0 {
+		return nil, fmt.Errorf("tile data is %d bytes, not a multiple of 4", len(raw))
+	}
+	gids := make([]uint32, len(raw)/4)
+	for i := range gids {
+		gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+	}
+	return gids, nil
+}
+
+// property looks up a custom property by name
+func property(props []tiledProperty, name string) (tiledProperty, bool) {
+	for _, prop := range props {
+		if prop.Name == name {
+			return prop, true
+		}
+	}
+	return tiledProperty{}, false
+}
+
+func stringProperty(props []tiledProperty, name, fallback string) string {
+	if prop, ok := property(props, name); ok {
+		if value, ok := prop.Value.(string); ok {
+			return value
+		}
+	}
+	return fallback
+}
+
+func boolProperty(props []tiledProperty, name string, fallback bool) bool {
+	if prop, ok := property(props, name); ok {
+		switch value := prop.Value.(type) {
+		case bool:
+			return value
+		case string:
+			return value == "true"
+		}
+	}
+	return fallback
+}
+
+func floatProperty(props []tiledProperty, name string, fallback float64) float64 {
+	if prop, ok := property(props, name); ok {
+		switch value := prop.Value.(type) {
+		case float64:
+			return value
+		case int:
+			return float64(value)
+		}
+	}
+	return fallback
+}
+
+func intProperty(props []tiledProperty, name string, fallback int) int {
+	return int(floatProperty(props, name, float64(fallback)))
+}
+
+// resolvePath makes a path from the map relative to the directory it's in,
+// Tiled writes paths relative to the file referencing them unless they're absolute
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,119 @@
+package level
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// Tiles of a small layer, with a horizontally flipped tile kept as is
+var testGIDs = []uint32{0, 1, 2, 3, 0x80000004, 0}
+
+// encodeGIDs encodes gids the way Tiled does, compressed with compression
+func encodeGIDs(t *testing.T, gids []uint32, compression string) string {
+	raw := make([]byte, len(gids)*4)
+	for i, gid := range gids {
+		binary.LittleEndian.PutUint32(raw[i*4:], gid)
+	}
+
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch compression {
+	case "":
+		buf.Write(raw)
+	case "zlib":
+		writer = zlib.NewWriter(&buf)
+	case "gzip":
+		writer = gzip.NewWriter(&buf)
+	}
+	if writer != nil {
+		if _, err := writer.Write(raw); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestDecodeBase64TileData(t *testing.T) {
+	tests := []struct {
+		name        string
+		encoded     string
+		compression string
+		want        []uint32
+		wantErr     bool
+	}{
+		{name: "uncompressed", encoded: encodeGIDs(t, testGIDs, ""), want: testGIDs},
+		{name: "zlib", encoded: encodeGIDs(t, testGIDs, "zlib"), compression: "zlib", want: testGIDs},
+		{name: "gzip", encoded: encodeGIDs(t, testGIDs, "gzip"), compression: "gzip", want: testGIDs},
+		{name: "surrounded by whitespace", encoded: "\n   " + encodeGIDs(t, testGIDs, "") + "\n  ", want: testGIDs},
+		{name: "not base64", encoded: "!!!", wantErr: true},
+		{name: "unsupported compression", encoded: encodeGIDs(t, testGIDs, ""), compression: "zstd", wantErr: true},
+		{name: "not compressed as claimed", encoded: encodeGIDs(t, testGIDs, ""), compression: "zlib", wantErr: true},
+		{name: "partial tile", encoded: base64.StdEncoding.EncodeToString([]byte{1, 0, 0, 0, 2, 0}), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gids, err := decodeBase64TileData(tt.encoded, tt.compression)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %v, want an error", gids)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gids, tt.want) {
+				t.Errorf("got %v, want %v", gids, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeTMXTileData(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string // A <data> element of a TMX layer
+		want    []uint32
+		wantErr bool
+	}{
+		{name: "csv", data: "<data encoding=\"csv\">\n0,1,2,\n3,2147483652,0\n</data>", want: testGIDs},
+		{name: "base64", data: `<data encoding="base64">` + encodeGIDs(t, testGIDs, "") + `</data>`, want: testGIDs},
+		{name: "base64 zlib", data: `<data encoding="base64" compression="zlib">` + encodeGIDs(t, testGIDs, "zlib") + `</data>`, want: testGIDs},
+		{name: "tile elements", data: `<data><tile/><tile gid="1"/><tile gid="2"/><tile gid="3"/><tile gid="2147483652"/><tile/></data>`, want: testGIDs},
+		{name: "csv with a bad number", data: `<data encoding="csv">1,two,3</data>`, wantErr: true},
+		{name: "csv out of range", data: `<data encoding="csv">4294967296</data>`, wantErr: true},
+		{name: "no tiles", data: `<data></data>`, wantErr: true},
+		{name: "unsupported encoding", data: `<data encoding="hex">00</data>`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data tmxData
+			if err := xml.Unmarshal([]byte(tt.data), &data); err != nil {
+				t.Fatal(err)
+			}
+			gids, err := decodeTMXTileData(data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %v, want an error", gids)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gids, tt.want) {
+				t.Errorf("got %v, want %v", gids, tt.want)
+			}
+		})
+	}
+}
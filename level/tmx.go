@@ -0,0 +1,308 @@
+package level
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The tmx* types mirror Tiled's XML map format and are only used to convert
+// TMX files into the same structures JSON maps decode into
+
+type tmxMap struct {
+	Width           int           `xml:"width,attr"`
+	Height          int           `xml:"height,attr"`
+	TileWidth       int           `xml:"tilewidth,attr"`
+	TileHeight      int           `xml:"tileheight,attr"`
+	Infinite        int           `xml:"infinite,attr"`
+	ParallaxOriginX float32       `xml:"parallaxoriginx,attr"`
+	ParallaxOriginY float32       `xml:"parallaxoriginy,attr"`
+	Properties      []tmxProperty `xml:"properties>property"`
+	Tilesets        []tmxTileset  `xml:"tileset"`
+	Layers          []tmxLayer    `xml:",any"` // Layers of every kind, in drawing order
+}
+
+type tmxLayer struct {
+	XMLName    xml.Name
+	Name       string        `xml:"name,attr"`
+	Visible    string        `xml:"visible,attr"` // Missing means visible
+	Opacity    string        `xml:"opacity,attr"` // Missing means opaque
+	OffsetX    float32       `xml:"offsetx,attr"`
+	OffsetY    float32       `xml:"offsety,attr"`
+	ParallaxX  string        `xml:"parallaxx,attr"` // Missing means 1
+	ParallaxY  string        `xml:"parallaxy,attr"`
+	RepeatX    int           `xml:"repeatx,attr"`
+	RepeatY    int           `xml:"repeaty,attr"`
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Data       tmxData       `xml:"data"`
+	Objects    []tmxObject   `xml:"object"`
+	Image      tmxImage      `xml:"image"`
+	Layers     []tmxLayer    `xml:",any"` // Children of a group layer
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"` // Only used when the data isn't encoded
+	Content string `xml:",chardata"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float32       `xml:"x,attr"`
+	Y          float32       `xml:"y,attr"`
+	Width      float32       `xml:"width,attr"`
+	Height     float32       `xml:"height,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Point      *struct{}     `xml:"point"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxTileset struct {
+	FirstGID   uint32   `xml:"firstgid,attr"`
+	Source     string   `xml:"source,attr"`
+	Name       string   `xml:"name,attr"`
+	TileWidth  int      `xml:"tilewidth,attr"`
+	TileHeight int      `xml:"tileheight,attr"`
+	TileCount  int      `xml:"tilecount,attr"`
+	Columns    int      `xml:"columns,attr"`
+	Margin     int      `xml:"margin,attr"`
+	Spacing    int      `xml:"spacing,attr"`
+	Image      tmxImage `xml:"image"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Type  string `xml:"type,attr"`
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"` // Multi-line string properties
+}
+
+// readTMXMap parses a map saved in Tiled's XML format (.tmx)
+func readTMXMap(path string) (*tiledMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw tmxMap
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	m := &tiledMap{
+		Width:           raw.Width,
+		Height:          raw.Height,
+		TileWidth:       raw.TileWidth,
+		TileHeight:      raw.TileHeight,
+		Infinite:        raw.Infinite != 0,
+		Properties:      convertTMXProperties(raw.Properties),
+		ParallaxOriginX: raw.ParallaxOriginX,
+		ParallaxOriginY: raw.ParallaxOriginY,
+	}
+	dir := filepath.Dir(path)
+	for _, ts := range raw.Tilesets {
+		tileset := convertTMXTileset(ts)
+		if err := tileset.resolve(dir); err != nil {
+			return nil, err
+		}
+		m.Tilesets = append(m.Tilesets, tileset)
+	}
+	if m.Layers, err = convertTMXLayers(raw.Layers); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// readTSX parses an external tileset saved in Tiled's XML format (.tsx)
+func readTSX(path string) (*tiledTileset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw tmxTileset
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing tileset %s: %w", path, err)
+	}
+	tileset := convertTMXTileset(raw)
+	return &tileset, nil
+}
+
+func convertTMXTileset(raw tmxTileset) tiledTileset {
+	return tiledTileset{
+		FirstGID:    raw.FirstGID,
+		Source:      raw.Source,
+		Name:        raw.Name,
+		Image:       raw.Image.Source,
+		ImageWidth:  raw.Image.Width,
+		ImageHeight: raw.Image.Height,
+		TileWidth:   raw.TileWidth,
+		TileHeight:  raw.TileHeight,
+		TileCount:   raw.TileCount,
+		Columns:     raw.Columns,
+		Margin:      raw.Margin,
+		Spacing:     raw.Spacing,
+	}
+}
+
+// parseParallax reads a layer's parallax factor, nil when it's left out
+func parseParallax(attr string) (*float32, error) {
+	if attr == "" {
+		return nil, nil
+	}
+	factor, err := strconv.ParseFloat(attr, 32)
+	if err != nil {
+		return nil, err
+	}
+	parallax := float32(factor)
+	return &parallax, nil
+}
+
+func convertTMXLayers(rawLayers []tmxLayer) ([]tiledLayer, error) {
+	var layers []tiledLayer
+	for _, raw := range rawLayers {
+		layer := tiledLayer{
+			Name:       raw.Name,
+			Visible:    raw.Visible != "0",
+			Opacity:    1,
+			OffsetX:    raw.OffsetX,
+			OffsetY:    raw.OffsetY,
+			RepeatX:    raw.RepeatX != 0,
+			RepeatY:    raw.RepeatY != 0,
+			Width:      raw.Width,
+			Height:     raw.Height,
+			Properties: convertTMXProperties(raw.Properties),
+		}
+		if raw.Opacity != "" {
+			opacity, err := strconv.ParseFloat(raw.Opacity, 32)
+			if err != nil {
+				return nil, fmt.Errorf("layer %q: %w", raw.Name, err)
+			}
+			layer.Opacity = float32(opacity)
+		}
+		var err error
+		if layer.ParallaxX, err = parseParallax(raw.ParallaxX); err != nil {
+			return nil, fmt.Errorf("layer %q: %w", raw.Name, err)
+		}
+		if layer.ParallaxY, err = parseParallax(raw.ParallaxY); err != nil {
+			return nil, fmt.Errorf("layer %q: %w", raw.Name, err)
+		}
+
+		switch raw.XMLName.Local {
+		case "layer":
+			layer.Type = "tilelayer"
+			gids, err := decodeTMXTileData(raw.Data)
+			if err != nil {
+				return nil, fmt.Errorf("layer %q: %w", raw.Name, err)
+			}
+			layer.gids = gids
+		case "objectgroup":
+			layer.Type = "objectgroup"
+			for _, obj := range raw.Objects {
+				layer.Objects = append(layer.Objects, tiledObject{
+					ID:         obj.ID,
+					Name:       obj.Name,
+					Type:       obj.Type,
+					Class:      obj.Class,
+					X:          obj.X,
+					Y:          obj.Y,
+					Width:      obj.Width,
+					Height:     obj.Height,
+					Point:      obj.Point != nil,
+					GID:        obj.GID,
+					Properties: convertTMXProperties(obj.Properties),
+				})
+			}
+		case "imagelayer":
+			layer.Type = "imagelayer"
+			layer.Image = raw.Image.Source
+		case "group":
+			layer.Type = "group"
+			children, err := convertTMXLayers(raw.Layers)
+			if err != nil {
+				return nil, err
+			}
+			layer.Layers = children
+		default:
+			continue // Not a layer, like <editorsettings>
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+func decodeTMXTileData(data tmxData) ([]uint32, error) {
+	switch data.Encoding {
+	case "csv":
+		fields := strings.FieldsFunc(data.Content, func(r rune) bool {
+			return r == ',' || r == '\n' || r == '\r' || r == ' ' || r == '\t'
+		})
+		gids := make([]uint32, len(fields))
+		for i, field := range fields {
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids[i] = uint32(gid)
+		}
+		return gids, nil
+	case "base64":
+		return decodeBase64TileData(data.Content, data.Compression)
+	case "":
+		if len(data.Tiles) == 0 {
+			return nil, fmt.Errorf("no tile data, infinite maps are not supported")
+		}
+		gids := make([]uint32, len(data.Tiles))
+		for i, tile := range data.Tiles {
+			gids[i] = tile.GID
+		}
+		return gids, nil
+	default:
+		return nil, fmt.Errorf("unsupported tile data encoding %q", data.Encoding)
+	}
+}
+
+// convertTMXProperties types property values the way Tiled's JSON export does
+func convertTMXProperties(raw []tmxProperty) []tiledProperty {
+	props := make([]tiledProperty, 0, len(raw))
+	for _, p := range raw {
+		prop := tiledProperty{Name: p.Name, Type: p.Type}
+		if prop.Type == "" {
+			prop.Type = "string"
+		}
+		value := p.Value
+		if value == "" {
+			value = p.Text
+		}
+
+		switch prop.Type {
+		case "bool":
+			prop.Value = value == "true"
+		case "int", "float":
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				number = 0
+			}
+			prop.Value = number
+		default:
+			prop.Value = value
+		}
+		props = append(props, prop)
+	}
+	return props
+}
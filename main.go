@@ -2,41 +2,34 @@ package main
 
 import (
 	"platformer-game/core"
-	"platformer-game/gameobjects"
-	"time"
+	"platformer-game/input"
+	"platformer-game/resources"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
-
 const (
 	screenWidth  = 800
 	screenHeight = 450
-	worldWidth   = 5000
-	worldHeight  = 1200
 )
 
-var gameOver bool
-
 func main() {
 	rl.InitWindow(screenWidth, screenHeight, "Platformer Game")
 	defer rl.CloseWindow()
+	rl.InitAudioDevice() // Initialize audio device once for every run
+	defer rl.CloseAudioDevice()
+	rl.SetExitKey(0) // Esc pauses the game instead of closing the window
 
-	core.InitGame(worldWidth, worldHeight)
+	input.LoadUserConfig() // Key bindings changed in the controls menu
+	core.SetTickRate(core.DefaultTickRate)
+	core.PushScene(core.NewTitleScene())
 
-	for !rl.WindowShouldClose() && !gameOver {
-		core.UpdateGame(worldHeight) //need to pass worldHeight to update zombies 
-		gameOver = gameobjects.PlayerInstance.IsGameOver() // Check game-over condition
-		core.DrawGame()
+	for !rl.WindowShouldClose() && !core.ShouldQuit() {
+		core.Step(rl.GetFrameTime()) // Runs fixed-timestep updates for this frame
+		core.Draw()
 	}
-	//check players health
-	
-	// Display "Game Over" message if game has ended
-    if gameOver {
-        rl.BeginDrawing()
-        rl.ClearBackground(rl.Black)
-        rl.DrawText("Game Over", screenWidth/2-50, screenHeight/2-20, 40, rl.Red)
-        rl.EndDrawing()
-		time.Sleep(3 * time.Second) // Delay to show message before closing
-    }
+
+	// Exit every scene so the current run frees its textures and sounds
+	core.Shutdown()
+	resources.UnloadAll() // Anything still loaded now is a leak and gets reported
 }
@@ -1,9 +1,188 @@
 package physics
 
-import rl "github.com/gen2brain/raylib-go/raylib"
+import (
+	"math"
 
-// Checks if the player is on the ground
-func IsOnGround(position rl.Vector2, height float32) bool {
-	groundY := float32(450) // Defining ground level based on screen size
-	return position.Y+height >= groundY
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// epsilon keeps boxes that exactly touch a surface from counting as inside it
+const epsilon = 0.001
+
+// Contacts reports which sides of a box were blocked during a move
+type Contacts struct {
+	OnGround   bool // Landed on, or is standing on, something below
+	HitCeiling bool // Bumped into something above
+	HitLeft    bool
+	HitRight   bool
+}
+
+// Move sweeps box by delta and stops it at the first thing in its way,
+// returning where it ended up. The horizontal and vertical parts are resolved
+// one after the other, so a box sliding along the floor doesn't snag on tile
+// seams. Every tile between the start and the end is checked, so fast movers
+// can't tunnel through thin platforms. With dropThrough set, one-way
+// platforms are ignored so a character can drop down through them.
+func (w *World) Move(box rl.Rectangle, delta rl.Vector2, dropThrough bool) (rl.Rectangle, Contacts) {
+	var contacts Contacts
+
+	if delta.X != 0 {
+		dx, hit := w.sweepX(box, delta.X)
+		box.X += dx
+		contacts.HitRight = hit && delta.X > 0
+		contacts.HitLeft = hit && delta.X < 0
+	}
+	if delta.Y != 0 {
+		dy, hit := w.sweepY(box, delta.Y, dropThrough)
+		box.Y += dy
+		contacts.OnGround = hit && delta.Y > 0
+		contacts.HitCeiling = hit && delta.Y < 0
+	}
+	return box, contacts
+}
+
+// sweepX returns how far box can move horizontally, up to dx, and whether
+// something blocked it. One-way platforms never block sideways movement.
+func (w *World) sweepX(box rl.Rectangle, dx float32) (float32, bool) {
+	firstRow := int(floorDiv(box.Y+epsilon, w.TileHeight))
+	lastRow := int(floorDiv(box.Y+box.Height-epsilon, w.TileHeight))
+	allowed, hit := dx, false
+
+	if dx > 0 {
+		edge := box.X + box.Width
+		// Start past the tile the edge is already in, so an overlapping box can still get out
+		for col := int(floorDiv(edge-epsilon, w.TileWidth)) + 1; float32(col)*w.TileWidth < edge+dx; col++ {
+			if w.columnBlocked(col, firstRow, lastRow) {
+				allowed, hit = float32(col)*w.TileWidth-edge, true
+				break
+			}
+		}
+		for _, platform := range w.Platforms {
+			r := platform.Rect
+			if platform.OneWay || !overlapsY(box, r) {
+				continue
+			}
+			if r.X >= edge-epsilon && r.X-edge < allowed {
+				allowed, hit = r.X-edge, true
+			}
+		}
+		if edge+allowed > w.Width {
+			allowed, hit = w.Width-edge, true
+		}
+		return max(allowed, 0), hit
+	}
+
+	edge := box.X
+	for col := int(floorDiv(edge+epsilon, w.TileWidth)) - 1; float32(col+1)*w.TileWidth > edge+dx; col-- {
+		if w.columnBlocked(col, firstRow, lastRow) {
+			allowed, hit = float32(col+1)*w.TileWidth-edge, true
+			break
+		}
+	}
+	for _, platform := range w.Platforms {
+		r := platform.Rect
+		if platform.OneWay || !overlapsY(box, r) {
+			continue
+		}
+		if r.X+r.Width <= edge+epsilon && r.X+r.Width-edge > allowed {
+			allowed, hit = r.X+r.Width-edge, true
+		}
+	}
+	if edge+allowed < 0 {
+		allowed, hit = -edge, true
+	}
+	return min(allowed, 0), hit
+}
+
+// sweepY returns how far box can move vertically, up to dy, and whether
+// something blocked it. One-way platforms only block a box moving down that
+// started above them.
+func (w *World) sweepY(box rl.Rectangle, dy float32, dropThrough bool) (float32, bool) {
+	firstCol := int(floorDiv(box.X+epsilon, w.TileWidth))
+	lastCol := int(floorDiv(box.X+box.Width-epsilon, w.TileWidth))
+	allowed, hit := dy, false
+
+	if dy > 0 {
+		edge := box.Y + box.Height
+		for row := int(floorDiv(edge-epsilon, w.TileHeight)) + 1; float32(row)*w.TileHeight < edge+dy; row++ {
+			if w.rowBlocked(row, firstCol, lastCol, !dropThrough) {
+				allowed, hit = float32(row)*w.TileHeight-edge, true
+				break
+			}
+		}
+		for _, platform := range w.Platforms {
+			r := platform.Rect
+			if (platform.OneWay && dropThrough) || !overlapsX(box, r) {
+				continue
+			}
+			if r.Y >= edge-epsilon && r.Y-edge < allowed {
+				allowed, hit = r.Y-edge, true
+			}
+		}
+		if edge+allowed > w.Height {
+			allowed, hit = w.Height-edge, true
+		}
+		return max(allowed, 0), hit
+	}
+
+	edge := box.Y
+	for row := int(floorDiv(edge+epsilon, w.TileHeight)) - 1; float32(row+1)*w.TileHeight > edge+dy; row-- {
+		if row < 0 {
+			break // Nothing above the map
+		}
+		if w.rowBlocked(row, firstCol, lastCol, false) {
+			allowed, hit = float32(row+1)*w.TileHeight-edge, true
+			break
+		}
+	}
+	for _, platform := range w.Platforms {
+		r := platform.Rect
+		if platform.OneWay || !overlapsX(box, r) {
+			continue
+		}
+		if r.Y+r.Height <= edge+epsilon && r.Y+r.Height-edge > allowed {
+			allowed, hit = r.Y+r.Height-edge, true
+		}
+	}
+	return min(allowed, 0), hit
+}
+
+// columnBlocked reports whether any solid tile sits in col between the two rows
+func (w *World) columnBlocked(col, firstRow, lastRow int) bool {
+	for row := firstRow; row <= lastRow; row++ {
+		if w.TileAt(col, row) == Solid {
+			return true
+		}
+	}
+	return false
+}
+
+// rowBlocked reports whether any tile in row between the two columns blocks,
+// counting one-way tiles only when oneWay is set
+func (w *World) rowBlocked(row, firstCol, lastCol int, oneWay bool) bool {
+	for col := firstCol; col <= lastCol; col++ {
+		switch w.TileAt(col, row) {
+		case Solid:
+			return true
+		case OneWay:
+			if oneWay {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func overlapsX(a, b rl.Rectangle) bool {
+	return a.X < b.X+b.Width-epsilon && b.X < a.X+a.Width-epsilon
+}
+
+func overlapsY(a, b rl.Rectangle) bool {
+	return a.Y < b.Y+b.Height-epsilon && b.Y < a.Y+a.Height-epsilon
+}
+
+// floorDiv divides and rounds toward negative infinity, so positions left of
+// or above the map land in negative tiles instead of tile 0
+func floorDiv(value, size float32) float32 {
+	return float32(math.Floor(float64(value / size)))
 }
@@ -0,0 +1,93 @@
+package physics
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// RayHit is where a segment first runs into solid geometry
+type RayHit struct {
+	Point  rl.Vector2 // Where the segment enters the surface
+	Normal rl.Vector2 // Unit vector pointing out of the surface that was hit
+	T      float32    // How far along the segment the hit is, from 0 to 1
+}
+
+// Raycast finds the first solid tile or platform the segment from a to b runs
+// into. One-way platforms are ignored, and so is anything a already starts
+// inside of, so a ray can always get out of a wall.
+func (w *World) Raycast(a, b rl.Vector2) (RayHit, bool) {
+	delta := rl.Vector2Subtract(b, a)
+	best, found := RayHit{T: 2}, false
+	check := func(rect rl.Rectangle) {
+		if hit, ok := segmentRect(a, delta, rect); ok && hit.T < best.T {
+			best, found = hit, true
+		}
+	}
+
+	// Only the tiles the segment's bounds cover can be in its way
+	firstCol := int(floorDiv(min(a.X, b.X), w.TileWidth))
+	lastCol := int(floorDiv(max(a.X, b.X), w.TileWidth))
+	firstRow := int(floorDiv(min(a.Y, b.Y), w.TileHeight))
+	lastRow := int(floorDiv(max(a.Y, b.Y), w.TileHeight))
+	for row := firstRow; row <= lastRow; row++ {
+		for col := firstCol; col <= lastCol; col++ {
+			if w.TileAt(col, row) == Solid {
+				check(rl.Rectangle{
+					X:      float32(col) * w.TileWidth,
+					Y:      float32(row) * w.TileHeight,
+					Width:  w.TileWidth,
+					Height: w.TileHeight,
+				})
+			}
+		}
+	}
+	for _, platform := range w.Platforms {
+		if !platform.OneWay {
+			check(platform.Rect)
+		}
+	}
+
+	best.Point = rl.Vector2Add(a, rl.Vector2Scale(delta, best.T))
+	return best, found
+}
+
+// segmentRect intersects the segment from a along delta with rect, using the
+// slab method: the segment is inside rect where it's between both pairs of
+// edges at once, and enters it where the later of the two entries is.
+func segmentRect(a, delta rl.Vector2, rect rl.Rectangle) (RayHit, bool) {
+	tEnter, tExit := float32(-1), float32(2)
+	var normal rl.Vector2
+
+	axes := [2]struct{ start, delta, low, high float32 }{
+		{a.X, delta.X, rect.X, rect.X + rect.Width},
+		{a.Y, delta.Y, rect.Y, rect.Y + rect.Height},
+	}
+	for i, axis := range axes {
+		if axis.delta == 0 {
+			if axis.start <= axis.low || axis.start >= axis.high {
+				return RayHit{}, false // Parallel to this pair of edges and outside them
+			}
+			continue
+		}
+		near, far := (axis.low-axis.start)/axis.delta, (axis.high-axis.start)/axis.delta
+		side := float32(-1) // Entering through the low edge, the surface faces the low side
+		if near > far {
+			near, far = far, near
+			side = 1
+		}
+		if near > tEnter {
+			tEnter = near
+			normal = rl.Vector2{}
+			if i == 0 {
+				normal.X = side
+			} else {
+				normal.Y = side
+			}
+		}
+		tExit = min(tExit, far)
+	}
+
+	if tEnter < 0 || tEnter > 1 || tEnter > tExit {
+		return RayHit{}, false // Starts inside, ends before it, or misses
+	}
+	return RayHit{Normal: normal, T: tEnter}, true
+}
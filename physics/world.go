@@ -0,0 +1,100 @@
+package physics
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+// TileKind describes how a tile of the collision grid blocks movement
+type TileKind uint8
+
+const (
+	Empty  TileKind = iota
+	Solid           // Blocks from every side
+	OneWay          // Only blocks things landing on it from above
+)
+
+// Platform is a collision rectangle that doesn't fit the tile grid
+type Platform struct {
+	Rect   rl.Rectangle
+	OneWay bool
+}
+
+// World is the static geometry characters collide with: a tile grid plus
+// free-standing rectangles. The left, right and bottom edges of the world act
+// as walls, the top is open so characters can jump above the map.
+type World struct {
+	Width, Height         float32 // Size in pixels
+	TileWidth, TileHeight float32
+	Columns, Rows         int
+	Tiles                 []TileKind // Columns*Rows, row by row
+	Platforms             []Platform
+}
+
+// NewWorld creates an empty world of columns x rows tiles
+func NewWorld(columns, rows int, tileWidth, tileHeight float32) *World {
+	return &World{
+		Width:      float32(columns) * tileWidth,
+		Height:     float32(rows) * tileHeight,
+		TileWidth:  tileWidth,
+		TileHeight: tileHeight,
+		Columns:    columns,
+		Rows:       rows,
+		Tiles:      make([]TileKind, columns*rows),
+	}
+}
+
+// SetTile changes the kind of the tile at col, row
+func (w *World) SetTile(col, row int, kind TileKind) {
+	if col < 0 || row < 0 || col >= w.Columns || row >= w.Rows {
+		return
+	}
+	w.Tiles[row*w.Columns+col] = kind
+}
+
+// TileAt returns the kind of the tile at col, row; outside the map is empty
+func (w *World) TileAt(col, row int) TileKind {
+	if col < 0 || row < 0 || col >= w.Columns || row >= w.Rows {
+		return Empty
+	}
+	return w.Tiles[row*w.Columns+col]
+}
+
+// IsSolidAt reports whether the point is inside solid geometry, one-way
+// platforms don't count
+func (w *World) IsSolidAt(point rl.Vector2) bool {
+	col := int(floorDiv(point.X, w.TileWidth))
+	row := int(floorDiv(point.Y, w.TileHeight))
+	if w.TileAt(col, row) == Solid {
+		return true
+	}
+	for _, platform := range w.Platforms {
+		if !platform.OneWay && rl.CheckCollisionPointRec(point, platform.Rect) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOnGround reports whether box is resting on something it can stand on
+func (w *World) IsOnGround(box rl.Rectangle) bool {
+	_, contacts := w.Move(box, rl.Vector2{Y: 1}, false)
+	return contacts.OnGround
+}
+
+// Overlaps reports whether box is inside solid geometry anywhere, one-way
+// platforms don't count
+func (w *World) Overlaps(box rl.Rectangle) bool {
+	firstCol := int(floorDiv(box.X+epsilon, w.TileWidth))
+	lastCol := int(floorDiv(box.X+box.Width-epsilon, w.TileWidth))
+	firstRow := int(floorDiv(box.Y+epsilon, w.TileHeight))
+	lastRow := int(floorDiv(box.Y+box.Height-epsilon, w.TileHeight))
+	for col := firstCol; col <= lastCol; col++ {
+		if w.columnBlocked(col, firstRow, lastRow) {
+			return true
+		}
+	}
+	for _, platform := range w.Platforms {
+		if !platform.OneWay && overlapsX(box, platform.Rect) && overlapsY(box, platform.Rect) {
+			return true
+		}
+	}
+	return false
+}
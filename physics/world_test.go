@@ -0,0 +1,103 @@
+package physics
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// testWorld is 10x10 tiles of 32 pixels: a solid floor along the bottom row, a
+// solid wall down column 8, one-way tiles in row 6 from column 2 to 4, a solid
+// platform hanging at 128,64 and a one-way platform at 160,240.
+func testWorld() *World {
+	w := NewWorld(10, 10, 32, 32)
+	for col := 0; col < 10; col++ {
+		w.SetTile(col, 9, Solid)
+	}
+	for row := 0; row < 10; row++ {
+		w.SetTile(8, row, Solid)
+	}
+	for col := 2; col <= 4; col++ {
+		w.SetTile(col, 6, OneWay)
+	}
+	w.Platforms = []Platform{
+		{Rect: rl.Rectangle{X: 128, Y: 64, Width: 64, Height: 16}},
+		{Rect: rl.Rectangle{X: 160, Y: 240, Width: 64, Height: 8}, OneWay: true},
+	}
+	return w
+}
+
+func TestMove(t *testing.T) {
+	tests := []struct {
+		name        string
+		at          rl.Vector2 // Top-left of a 20x30 box
+		delta       rl.Vector2
+		dropThrough bool
+		want        rl.Vector2
+		contacts    Contacts
+	}{
+		{name: "falls onto the floor", at: rl.Vector2{X: 40, Y: 200}, delta: rl.Vector2{Y: 100}, want: rl.Vector2{X: 40, Y: 258}, contacts: Contacts{OnGround: true}},
+		{name: "lands on a one-way tile", at: rl.Vector2{X: 70, Y: 100}, delta: rl.Vector2{Y: 100}, want: rl.Vector2{X: 70, Y: 162}, contacts: Contacts{OnGround: true}},
+		{name: "drops through a one-way tile", at: rl.Vector2{X: 70, Y: 100}, delta: rl.Vector2{Y: 100}, dropThrough: true, want: rl.Vector2{X: 70, Y: 200}},
+		{name: "jumps up through a one-way tile", at: rl.Vector2{X: 70, Y: 200}, delta: rl.Vector2{Y: -100}, want: rl.Vector2{X: 70, Y: 100}},
+		{name: "already past a one-way top keeps falling", at: rl.Vector2{X: 70, Y: 170}, delta: rl.Vector2{Y: 20}, want: rl.Vector2{X: 70, Y: 190}},
+		{name: "stands on a one-way tile", at: rl.Vector2{X: 70, Y: 162}, delta: rl.Vector2{Y: 1}, want: rl.Vector2{X: 70, Y: 162}, contacts: Contacts{OnGround: true}},
+		{name: "fast fall doesn't tunnel", at: rl.Vector2{X: 70, Y: 0}, delta: rl.Vector2{Y: 1000}, want: rl.Vector2{X: 70, Y: 162}, contacts: Contacts{OnGround: true}},
+		{name: "lands on a one-way platform", at: rl.Vector2{X: 170, Y: 150}, delta: rl.Vector2{Y: 100}, want: rl.Vector2{X: 170, Y: 210}, contacts: Contacts{OnGround: true}},
+		{name: "drops through a one-way platform", at: rl.Vector2{X: 170, Y: 150}, delta: rl.Vector2{Y: 100}, dropThrough: true, want: rl.Vector2{X: 170, Y: 250}},
+		{name: "bumps into a solid platform", at: rl.Vector2{X: 140, Y: 100}, delta: rl.Vector2{Y: -100}, want: rl.Vector2{X: 140, Y: 80}, contacts: Contacts{HitCeiling: true}},
+		{name: "walks into a wall", at: rl.Vector2{X: 200, Y: 100}, delta: rl.Vector2{X: 100}, want: rl.Vector2{X: 236, Y: 100}, contacts: Contacts{HitRight: true}},
+		{name: "walks sideways through one-way tiles", at: rl.Vector2{X: 20, Y: 180}, delta: rl.Vector2{X: 100}, want: rl.Vector2{X: 120, Y: 180}},
+		{name: "stops at the left edge of the world", at: rl.Vector2{X: 10, Y: 100}, delta: rl.Vector2{X: -50}, want: rl.Vector2{X: 0, Y: 100}, contacts: Contacts{HitLeft: true}},
+		{name: "slides down a wall it touches", at: rl.Vector2{X: 236, Y: 100}, delta: rl.Vector2{X: 10, Y: 50}, want: rl.Vector2{X: 236, Y: 150}, contacts: Contacts{HitRight: true}},
+		{name: "slides along the floor without snagging", at: rl.Vector2{X: 40, Y: 258}, delta: rl.Vector2{X: 50, Y: 1}, want: rl.Vector2{X: 90, Y: 258}, contacts: Contacts{OnGround: true}},
+		{name: "lands in the corner of floor and wall", at: rl.Vector2{X: 220, Y: 200}, delta: rl.Vector2{X: 40, Y: 100}, want: rl.Vector2{X: 236, Y: 258}, contacts: Contacts{OnGround: true, HitRight: true}},
+	}
+
+	w := testWorld()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box := rl.Rectangle{X: tt.at.X, Y: tt.at.Y, Width: 20, Height: 30}
+			got, contacts := w.Move(box, tt.delta, tt.dropThrough)
+			if got.X != tt.want.X || got.Y != tt.want.Y {
+				t.Errorf("ended at %v,%v, want %v,%v", got.X, got.Y, tt.want.X, tt.want.Y)
+			}
+			if contacts != tt.contacts {
+				t.Errorf("contacts %+v, want %+v", contacts, tt.contacts)
+			}
+		})
+	}
+}
+
+func TestRaycast(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   rl.Vector2
+		hit    bool
+		point  rl.Vector2
+		normal rl.Vector2
+	}{
+		{name: "down past a one-way tile to the floor", a: rl.Vector2{X: 80, Y: 100}, b: rl.Vector2{X: 80, Y: 400}, hit: true, point: rl.Vector2{X: 80, Y: 288}, normal: rl.Vector2{Y: -1}},
+		{name: "into the wall", a: rl.Vector2{X: 100, Y: 100}, b: rl.Vector2{X: 400, Y: 100}, hit: true, point: rl.Vector2{X: 256, Y: 100}, normal: rl.Vector2{X: -1}},
+		{name: "up into a solid platform", a: rl.Vector2{X: 150, Y: 200}, b: rl.Vector2{X: 150, Y: 0}, hit: true, point: rl.Vector2{X: 150, Y: 80}, normal: rl.Vector2{Y: 1}},
+		{name: "out of the wall it starts in", a: rl.Vector2{X: 260, Y: 100}, b: rl.Vector2{X: 310, Y: 100}},
+		{name: "through open air", a: rl.Vector2{X: 0, Y: 10}, b: rl.Vector2{X: 100, Y: 10}},
+		{name: "stops short of the floor", a: rl.Vector2{X: 40, Y: 100}, b: rl.Vector2{X: 40, Y: 280}},
+	}
+
+	w := testWorld()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hit, ok := w.Raycast(tt.a, tt.b)
+			if ok != tt.hit {
+				t.Fatalf("hit %v, want %v", ok, tt.hit)
+			}
+			if !ok {
+				return
+			}
+			if rl.Vector2Distance(hit.Point, tt.point) > 0.01 || hit.Normal != tt.normal {
+				t.Errorf("hit %v facing %v, want %v facing %v", hit.Point, hit.Normal, tt.point, tt.normal)
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package rendering
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"platformer-game/resources"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// defaultFrameDuration is used when neither a frame nor its clip set a duration
+const defaultFrameDuration = 0.1
+
+// Frame is one frame of an animation clip
+type Frame struct {
+	Source   rl.Rectangle // Where the frame sits in the sprite sheet
+	Duration float32      // Seconds the frame stays on screen
+}
+
+// Clip is a named animation, like "walk" or "die"
+type Clip struct {
+	Name    string
+	Texture rl.Texture2D
+	Frames  []Frame
+	Loop    bool       // Loop forever, or stop on the last frame
+	Pivot   rl.Vector2 // Origin inside the frame from 0,0 (top-left) to 1,1 (bottom-right)
+}
+
+// AnimationSet holds every clip loaded from one animation file
+type AnimationSet struct {
+	Clips    map[string]*Clip
+	textures []rl.Texture2D
+}
+
+// animationFile is the game's own animation format:
+//
+//	{
+//	  "image": "../sprites/sheet.png",
+//	  "clips": {
+//	    "walk": {
+//	      "loop": true,
+//	      "frameDuration": 0.1,
+//	      "pivot": {"x": 0.5, "y": 0.5},
+//	      "frames": [{"x": 309, "y": 301, "w": 63, "h": 136}, ...]
+//	    }
+//	  }
+//	}
+//
+// A clip can set its own "image" if it comes from another sheet, and a frame
+// can set its own "duration". Image paths are relative to the file.
+type animationFile struct {
+	Image string              `json:"image"`
+	Clips map[string]clipSpec `json:"clips"`
+}
+
+type clipSpec struct {
+	Image         string      `json:"image"`
+	Loop          *bool       `json:"loop"` // Defaults to true
+	FrameDuration float32     `json:"frameDuration"`
+	Pivot         *pivotSpec  `json:"pivot"` // Defaults to the center
+	Frames        []frameSpec `json:"frames"`
+}
+
+type pivotSpec struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+type frameSpec struct {
+	X        float32 `json:"x"`
+	Y        float32 `json:"y"`
+	W        float32 `json:"w"`
+	H        float32 `json:"h"`
+	Duration float32 `json:"duration"`
+}
+
+// LoadAnimations reads an animation file, either the game's own format or a
+// JSON export from Aseprite (one clip per frame tag), and loads the sprite
+// sheets it uses. Sheets are shared with every other set using them through
+// the resources cache. Call Unload when done.
+func LoadAnimations(path string) (*AnimationSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe struct {
+		Meta json.RawMessage `json:"meta"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	set := &AnimationSet{Clips: map[string]*Clip{}}
+	if probe.Meta != nil {
+		err = set.loadAseprite(data, filepath.Dir(path))
+	} else {
+		err = set.loadClips(data, filepath.Dir(path))
+	}
+	if err != nil {
+		set.Unload()
+		return nil, fmt.Errorf("loading %s: %w", path, err)
+	}
+	return set, nil
+}
+
+func (s *AnimationSet) loadClips(data []byte, dir string) error {
+	var file animationFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	textures := map[string]rl.Texture2D{}
+	for name, spec := range file.Clips {
+		image := spec.Image
+		if image == "" {
+			image = file.Image
+		}
+		if image == "" {
+			return fmt.Errorf("clip %q has no image", name)
+		}
+		if len(spec.Frames) == 0 {
+			return fmt.Errorf("clip %q has no frames", name)
+		}
+		texture, ok := textures[image]
+		if !ok {
+			texture = s.loadTexture(filepath.Join(dir, image))
+			textures[image] = texture
+		}
+
+		clip := &Clip{
+			Name:    name,
+			Texture: texture,
+			Loop:    spec.Loop == nil || *spec.Loop,
+			Pivot:   rl.Vector2{X: 0.5, Y: 0.5},
+		}
+		if spec.Pivot != nil {
+			clip.Pivot = rl.Vector2{X: spec.Pivot.X, Y: spec.Pivot.Y}
+		}
+		for _, f := range spec.Frames {
+			duration := f.Duration
+			if duration <= 0 {
+				duration = spec.FrameDuration
+			}
+			if duration <= 0 {
+				duration = defaultFrameDuration
+			}
+			clip.Frames = append(clip.Frames, Frame{
+				Source:   rl.Rectangle{X: f.X, Y: f.Y, Width: f.W, Height: f.H},
+				Duration: duration,
+			})
+		}
+		s.Clips[name] = clip
+	}
+	return nil
+}
+
+// asepriteFile is the part of Aseprite's JSON export (File > Export Sprite
+// Sheet, with "Tags" enabled) the game uses. Frames can be exported as an
+// array or as a hash keyed by file name.
+type asepriteFile struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image     string `json:"image"`
+		FrameTags []struct {
+			Name      string `json:"name"`
+			From      int    `json:"from"`
+			To        int    `json:"to"`
+			Direction string `json:"direction"` // "forward", "reverse" or "pingpong"
+			Repeat    string `json:"repeat"`    // Empty or "0" loops forever
+		} `json:"frameTags"`
+	} `json:"meta"`
+}
+
+type asepriteFrame struct {
+	Filename string `json:"filename"`
+	Frame    struct {
+		X float32 `json:"x"`
+		Y float32 `json:"y"`
+		W float32 `json:"w"`
+		H float32 `json:"h"`
+	} `json:"frame"`
+	Duration int `json:"duration"` // Milliseconds
+}
+
+func (s *AnimationSet) loadAseprite(data []byte, dir string) error {
+	var file asepriteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	frames, err := asepriteFrames(file.Frames)
+	if err != nil {
+		return fmt.Errorf("frames: %w", err)
+	}
+
+	texture := s.loadTexture(filepath.Join(dir, file.Meta.Image))
+	for _, tag := range file.Meta.FrameTags {
+		if tag.From < 0 || tag.To >= len(frames) || tag.From > tag.To {
+			return fmt.Errorf("tag %q covers frames %d-%d, file has %d", tag.Name, tag.From, tag.To, len(frames))
+		}
+
+		var order []int
+		for i := tag.From; i <= tag.To; i++ {
+			order = append(order, i)
+		}
+		switch tag.Direction {
+		case "reverse":
+			for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+				order[i], order[j] = order[j], order[i]
+			}
+		case "pingpong":
+			for i := tag.To - 1; i > tag.From; i-- {
+				order = append(order, i)
+			}
+		}
+
+		clip := &Clip{
+			Name:    tag.Name,
+			Texture: texture,
+			Loop:    tag.Repeat == "" || tag.Repeat == "0",
+			Pivot:   rl.Vector2{X: 0.5, Y: 0.5},
+		}
+		for _, i := range order {
+			f := frames[i]
+			duration := float32(f.Duration) / 1000
+			if duration <= 0 {
+				duration = defaultFrameDuration
+			}
+			clip.Frames = append(clip.Frames, Frame{
+				Source:   rl.Rectangle{X: f.Frame.X, Y: f.Frame.Y, Width: f.Frame.W, Height: f.Frame.H},
+				Duration: duration,
+			})
+		}
+		s.Clips[tag.Name] = clip
+	}
+	return nil
+}
+
+// asepriteFrames decodes the frames of an Aseprite export in order, from an
+// array or from a hash keyed by file name. The hash is walked token by token
+// since a Go map would lose the order Aseprite wrote the frames in.
+func asepriteFrames(raw json.RawMessage) ([]asepriteFrame, error) {
+	var frames []asepriteFrame
+	if err := json.Unmarshal(raw, &frames); err == nil {
+		return frames, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("expected an array or an object of frames")
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var frame asepriteFrame
+		if err := decoder.Decode(&frame); err != nil {
+			return nil, fmt.Errorf("frame %q: %w", token, err)
+		}
+		frame.Filename = token.(string)
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+func (s *AnimationSet) loadTexture(path string) rl.Texture2D {
+	texture := resources.Texture(path)
+	s.textures = append(s.textures, texture)
+	return texture
+}
+
+// Unload releases the sprite sheets used by the set
+func (s *AnimationSet) Unload() {
+	for _, texture := range s.textures {
+		resources.ReleaseTexture(texture)
+	}
+	s.textures = nil
+}
+
+// Animator plays clips from an AnimationSet, one at a time
+type Animator struct {
+	Set      *AnimationSet
+	clip     *Clip
+	frame    int
+	timer    float32 // Seconds spent on the current frame
+	finished bool    // A non-looping clip reached its last frame
+}
+
+// NewAnimator creates an animator playing the named clip from set
+func NewAnimator(set *AnimationSet, clip string) Animator {
+	a := Animator{Set: set}
+	a.Play(clip)
+	return a
+}
+
+// Play switches to the named clip from the start, unless it's already playing
+func (a *Animator) Play(name string) {
+	if a.clip != nil && a.clip.Name == name {
+		return
+	}
+	a.clip = a.Set.Clips[name]
+	a.Restart()
+}
+
+// Restart plays the current clip again from its first frame
+func (a *Animator) Restart() {
+	a.frame = 0
+	a.timer = 0
+	a.finished = false
+}
+
+// Update advances the current clip by dt seconds
+func (a *Animator) Update(dt float32) {
+	if a.clip == nil || a.finished {
+		return
+	}
+	a.timer += dt
+	for a.timer >= a.clip.Frames[a.frame].Duration {
+		a.timer -= a.clip.Frames[a.frame].Duration
+		if a.frame < len(a.clip.Frames)-1 {
+			a.frame++
+		} else if a.clip.Loop {
+			a.frame = 0
+		} else {
+			a.finished = true // Hold the last frame
+			return
+		}
+	}
+}
+
+// Clip returns the name of the clip playing, or "" if there is none
+func (a *Animator) Clip() string {
+	if a.clip == nil {
+		return ""
+	}
+	return a.clip.Name
+}
+
+// Frame returns the index of the current frame within the clip
+func (a *Animator) Frame() int {
+	return a.frame
+}
+
+// Finished reports whether a non-looping clip has played to its end
+func (a *Animator) Finished() bool {
+	return a.finished
+}
+
+// Draw draws the current frame stretched to size, with the clip's pivot
+// placed at position. The frame is mirrored horizontally when flipX is set.
+func (a *Animator) Draw(position, size rl.Vector2, flipX bool, tint rl.Color) {
+	if a.clip == nil || a.clip.Texture.ID == 0 {
+		return
+	}
+	source := a.clip.Frames[a.frame].Source
+	if flipX {
+		source.Width = -source.Width
+	}
+	destination := rl.Rectangle{X: position.X, Y: position.Y, Width: size.X, Height: size.Y}
+	origin := rl.Vector2{X: a.clip.Pivot.X * size.X, Y: a.clip.Pivot.Y * size.Y}
+	rl.DrawTexturePro(a.clip.Texture, source, destination, origin, 0, tint)
+}
@@ -0,0 +1,53 @@
+package rendering
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAsepriteFrames(t *testing.T) {
+	// Twelve frames, so "run 10" and "run 11" would sort before "run 2" by name
+	var hash, array []string
+	for i := 0; i < 12; i++ {
+		frame := fmt.Sprintf(`{"frame": {"x": %d, "y": 0, "w": 16, "h": 16}, "duration": 100}`, i*16)
+		hash = append(hash, fmt.Sprintf(`"run %d.aseprite": %s`, i, frame))
+		array = append(array, frame)
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    int
+		wantErr bool
+	}{
+		{name: "hash keeps document order", raw: "{" + strings.Join(hash, ",") + "}", want: 12},
+		{name: "array", raw: "[" + strings.Join(array, ",") + "]", want: 12},
+		{name: "empty hash", raw: "{}", want: 0},
+		{name: "not frames", raw: `"run"`, wantErr: true},
+		{name: "bad frame", raw: `{"run 0.aseprite": 3}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames, err := asepriteFrames(json.RawMessage(tt.raw))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %d frames, want an error", len(frames))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(frames) != tt.want {
+				t.Fatalf("got %d frames, want %d", len(frames), tt.want)
+			}
+			for i, frame := range frames {
+				if frame.Frame.X != float32(i*16) {
+					t.Errorf("frame %d (%q) is at x %v, want %v", i, frame.Filename, frame.Frame.X, i*16)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package rendering
+
+import (
+	"math"
+	"math/rand"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// ParticleShape is what a particle is drawn as when its emitter has no texture
+type ParticleShape int
+
+const (
+	CircleParticle ParticleShape = iota
+	SquareParticle
+	StreakParticle // A line trailing behind the way it flies
+)
+
+// EmitterDef describes the particles an effect gives off. Speed, life and the
+// direction inside Spread are picked at random for each particle; color and
+// size go from their start to their end values over its life.
+type EmitterDef struct {
+	MinSpeed, MaxSpeed   float32 // Pixels per second
+	Spread               float32 // Degrees the particles fan out over around the emit direction, 360 for all round
+	Gravity              float32 // Pixels per second squared, negative to rise
+	Drag                 float32 // Share of the speed lost each second
+	MinLife, MaxLife     float32 // Seconds
+	StartColor, EndColor rl.Color
+	StartSize, EndSize   float32 // Radius, half width, or streak length, in pixels
+	Shape                ParticleShape
+	Texture              rl.Texture2D // Drawn instead of Shape when loaded, 2x size across and tinted by the color
+	Additive             bool         // Blended by adding light, for flashes and sparks
+	Burst                int          // Particles given off by each Burst
+	Rate                 float32      // Particles per second from a running Emitter
+}
+
+// particle is one live particle in a ParticleSystem
+type particle struct {
+	def      *EmitterDef
+	position rl.Vector2
+	velocity rl.Vector2
+	age      float32
+	life     float32
+}
+
+// ParticleSystem is a fixed pool of particles. Live particles are packed at
+// the front; when the pool is full new ones are dropped, so a busy scene loses
+// a few flecks instead of allocating.
+type ParticleSystem struct {
+	particles []particle
+	live      int
+}
+
+// NewParticleSystem makes a pool for up to capacity particles at once
+func NewParticleSystem(capacity int) *ParticleSystem {
+	return &ParticleSystem{particles: make([]particle, capacity)}
+}
+
+// Burst gives off def.Burst particles at position, aimed along direction
+func (s *ParticleSystem) Burst(def *EmitterDef, position, direction rl.Vector2) {
+	for i := 0; i < def.Burst; i++ {
+		s.emit(def, position, direction)
+	}
+}
+
+// emit starts one particle, if the pool has room
+func (s *ParticleSystem) emit(def *EmitterDef, position, direction rl.Vector2) {
+	if s.live == len(s.particles) {
+		return
+	}
+	facing := math.Atan2(float64(direction.Y), float64(direction.X))
+	angle := facing + (rand.Float64()-0.5)*float64(def.Spread)*math.Pi/180
+	speed := between(def.MinSpeed, def.MaxSpeed)
+	s.particles[s.live] = particle{
+		def:      def,
+		position: position,
+		velocity: rl.Vector2{X: float32(math.Cos(angle)) * speed, Y: float32(math.Sin(angle)) * speed},
+		life:     max(between(def.MinLife, def.MaxLife), 0.01),
+	}
+	s.live++
+}
+
+// Update moves and ages every particle, putting the ones that are done back in the pool
+func (s *ParticleSystem) Update(dt float32) {
+	for i := 0; i < s.live; {
+		p := &s.particles[i]
+		p.age += dt
+		if p.age >= p.life {
+			s.live--
+			s.particles[i] = s.particles[s.live] // Swap the last live particle into the gap
+			continue
+		}
+		p.velocity.Y += p.def.Gravity * dt
+		p.velocity = rl.Vector2Scale(p.velocity, max(1-p.def.Drag*dt, 0))
+		p.position = rl.Vector2Add(p.position, rl.Vector2Scale(p.velocity, dt))
+		i++
+	}
+}
+
+// Draw draws the particles inside view, the part of the world on screen.
+// Additive particles go last, in one pass, so they light up what's under them.
+func (s *ParticleSystem) Draw(view rl.Rectangle) {
+	for i := 0; i < s.live; i++ {
+		if !s.particles[i].def.Additive {
+			s.particles[i].draw(view)
+		}
+	}
+	rl.BeginBlendMode(rl.BlendAdditive)
+	for i := 0; i < s.live; i++ {
+		if s.particles[i].def.Additive {
+			s.particles[i].draw(view)
+		}
+	}
+	rl.EndBlendMode()
+}
+
+func (p *particle) draw(view rl.Rectangle) {
+	t := p.age / p.life
+	size := p.def.StartSize + (p.def.EndSize-p.def.StartSize)*t
+	bounds := rl.Rectangle{X: p.position.X - size, Y: p.position.Y - size, Width: size * 2, Height: size * 2}
+	if !rl.CheckCollisionRecs(bounds, view) {
+		return
+	}
+	color := lerpColor(p.def.StartColor, p.def.EndColor, t)
+
+	if p.def.Texture.ID != 0 {
+		source := rl.Rectangle{Width: float32(p.def.Texture.Width), Height: float32(p.def.Texture.Height)}
+		rl.DrawTexturePro(p.def.Texture, source, bounds, rl.Vector2{}, 0, color)
+		return
+	}
+	switch p.def.Shape {
+	case SquareParticle:
+		rl.DrawRectangleRec(bounds, color)
+	case StreakParticle:
+		tail := rl.Vector2Subtract(p.position, rl.Vector2Scale(rl.Vector2Normalize(p.velocity), size))
+		rl.DrawLineV(tail, p.position, color)
+	default:
+		rl.DrawCircleV(p.position, size, color)
+	}
+}
+
+// Clear drops every particle, when the level ends
+func (s *ParticleSystem) Clear() {
+	s.live = 0
+}
+
+// Emitter gives off particles continuously while Active, like smoke from a
+// fire. Move it by setting Position and Direction before each Update.
+type Emitter struct {
+	Def       *EmitterDef
+	Position  rl.Vector2
+	Direction rl.Vector2
+	Active    bool
+	owed      float32 // Particles due but not yet given off, so low rates still come out
+}
+
+// Update gives off the particles due over the last dt seconds into system
+func (e *Emitter) Update(dt float32, system *ParticleSystem) {
+	if !e.Active || e.Def == nil {
+		return
+	}
+	e.owed += e.Def.Rate * dt
+	for e.owed >= 1 {
+		system.emit(e.Def, e.Position, e.Direction)
+		e.owed--
+	}
+}
+
+// between picks a random value from low to high
+func between(low, high float32) float32 {
+	return low + rand.Float32()*(high-low)
+}
+
+// lerpColor blends from a to b as t goes from 0 to 1
+func lerpColor(a, b rl.Color, t float32) rl.Color {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float32(x) + (float32(y)-float32(x))*t)
+	}
+	return rl.Color{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: mix(a.A, b.A)}
+}
@@ -0,0 +1,170 @@
+// Package resources loads textures, images, sounds and music once per file
+// and shares them between everything that asks for the same path.
+//
+// Every Texture, Image, Sound or Music call takes a reference and must be
+// matched by a Release call with the value it returned; the asset is unloaded
+// when its last reference is released. UnloadAll frees whatever is left when
+// the game exits and logs each asset that was never released, so leaks show
+// up instead of going unnoticed.
+package resources
+
+import (
+	"log"
+	"path/filepath"
+	"sort"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// entry is a loaded asset shared by refs users
+type entry[T any] struct {
+	asset T
+	refs  int
+}
+
+// cache holds one kind of asset by path, plus a way back from an asset to
+// its path so callers can release what they were given
+type cache[T any, K comparable] struct {
+	kind    string
+	byPath  map[string]*entry[T]
+	paths   map[K]string
+	key     func(T) K
+	load    func(path string) T
+	unload  func(T)
+	invalid func(T) bool // Reports a failed load, which isn't cached
+}
+
+func newCache[T any, K comparable](kind string, key func(T) K, load func(string) T, unload func(T), invalid func(T) bool) *cache[T, K] {
+	return &cache[T, K]{
+		kind:    kind,
+		byPath:  map[string]*entry[T]{},
+		paths:   map[K]string{},
+		key:     key,
+		load:    load,
+		unload:  unload,
+		invalid: invalid,
+	}
+}
+
+var (
+	textures = newCache("texture",
+		func(t rl.Texture2D) uint32 { return t.ID },
+		rl.LoadTexture, rl.UnloadTexture,
+		func(t rl.Texture2D) bool { return t.ID == 0 })
+	images = newCache("image",
+		func(i *rl.Image) *rl.Image { return i },
+		rl.LoadImage, rl.UnloadImage,
+		func(i *rl.Image) bool { return i == nil || i.Data == nil })
+	sounds = newCache("sound",
+		func(s rl.Sound) *rl.AudioBuffer { return s.Stream.Buffer },
+		rl.LoadSound, rl.UnloadSound,
+		func(s rl.Sound) bool { return s.Stream.Buffer == nil })
+	music = newCache("music",
+		func(m rl.Music) *rl.AudioBuffer { return m.Stream.Buffer },
+		rl.LoadMusicStream, rl.UnloadMusicStream,
+		func(m rl.Music) bool { return m.Stream.Buffer == nil })
+)
+
+// acquire returns the asset at path, loading it on first use
+func (c *cache[T, K]) acquire(path string) T {
+	path = filepath.Clean(path) // "assets/levels/../sword.png" is the same file as "assets/sword.png"
+	if e, ok := c.byPath[path]; ok {
+		e.refs++
+		return e.asset
+	}
+	asset := c.load(path)
+	if c.invalid(asset) {
+		log.Printf("resources: failed to load %s %s", c.kind, path)
+		return asset
+	}
+	c.byPath[path] = &entry[T]{asset: asset, refs: 1}
+	c.paths[c.key(asset)] = path
+	return asset
+}
+
+// release drops a reference to asset, unloading it after the last one
+func (c *cache[T, K]) release(asset T) {
+	if c.invalid(asset) {
+		return
+	}
+	path, ok := c.paths[c.key(asset)]
+	if !ok {
+		log.Printf("resources: released a %s that wasn't loaded through the cache", c.kind)
+		return
+	}
+	e := c.byPath[path]
+	e.refs--
+	if e.refs > 0 {
+		return
+	}
+	c.unload(e.asset)
+	delete(c.byPath, path)
+	delete(c.paths, c.key(asset))
+}
+
+// unloadAll frees every asset still loaded, logging each one as a leak
+func (c *cache[T, K]) unloadAll() {
+	paths := make([]string, 0, len(c.byPath))
+	for path := range c.byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		e := c.byPath[path]
+		log.Printf("resources: leaked %s %s (%d references)", c.kind, path, e.refs)
+		c.unload(e.asset)
+	}
+	c.byPath = map[string]*entry[T]{}
+	c.paths = map[K]string{}
+}
+
+// Texture returns the texture at path, loading it if nothing else holds it
+func Texture(path string) rl.Texture2D {
+	return textures.acquire(path)
+}
+
+// ReleaseTexture gives back a texture returned by Texture
+func ReleaseTexture(texture rl.Texture2D) {
+	textures.release(texture)
+}
+
+// Image returns the CPU-side image at path, for cropping or reading pixels
+func Image(path string) *rl.Image {
+	return images.acquire(path)
+}
+
+// ReleaseImage gives back an image returned by Image
+func ReleaseImage(image *rl.Image) {
+	images.release(image)
+}
+
+// Sound returns the sound at path. Every user shares the same sound, so
+// playing it from one restarts it for all.
+func Sound(path string) rl.Sound {
+	return sounds.acquire(path)
+}
+
+// ReleaseSound gives back a sound returned by Sound
+func ReleaseSound(sound rl.Sound) {
+	sounds.release(sound)
+}
+
+// Music returns the music stream at path
+func Music(path string) rl.Music {
+	return music.acquire(path)
+}
+
+// ReleaseMusic gives back a music stream returned by Music
+func ReleaseMusic(stream rl.Music) {
+	music.release(stream)
+}
+
+// UnloadAll frees every asset still loaded and reports each as a leak. Call
+// it once on exit, after the game has released what it holds and before the
+// window and audio device are closed.
+func UnloadAll() {
+	textures.unloadAll()
+	images.unloadAll()
+	sounds.unloadAll()
+	music.unloadAll()
+}
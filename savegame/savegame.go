@@ -0,0 +1,222 @@
+// Package savegame reads and writes saved runs as versioned JSON files, one
+// per save slot.
+//
+// A save only holds plain data: textures and sounds are stored as the asset
+// paths they were loaded from and loaded again when the save is restored.
+// Whenever the format changes, Version goes up and a migration is added that
+// upgrades files written by the previous version, so old saves keep loading.
+package savegame
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Version is the save format written by this build
+const Version = 1
+
+// Dir is where save slots are kept
+const Dir = "saves"
+
+// QuickSlot is the slot used by quicksave and quickload
+const QuickSlot = "quick"
+
+// ErrNoSave is returned when reading a slot nothing was saved to
+var ErrNoSave = errors.New("no save in this slot")
+
+// File is a saved run
+type File struct {
+	Version   int         `json:"version"`
+	SavedAt   time.Time   `json:"savedAt"`
+	LevelID   string      `json:"levelId"`
+	LevelPath string      `json:"levelPath"`
+	Player    Player      `json:"player"`
+	Zombies   []Zombie    `json:"zombies"`
+	Items     []WorldItem `json:"items"`
+	Waves     Waves       `json:"waves"`
+}
+
+// Player is the saved player
+type Player struct {
+	Position     rl.Vector2 `json:"position"`
+	Speed        rl.Vector2 `json:"speed"`
+	FacingRight  bool       `json:"facingRight"`
+	Health       float64    `json:"health"`
+	MaxHealth    float64    `json:"maxHealth"`
+	Coins        int        `json:"coins"`
+	Score        int        `json:"score"`
+	Inventory    []Stack    `json:"inventory"` // One per slot, empty slots included
+	Held         Stack      `json:"held"`      // Stack held by the mouse, out of any slot
+	SelectedSlot int        `json:"selectedSlot"`
+	Guns         []Gun      `json:"guns"`
+	Buffs        []Buff     `json:"buffs"`
+}
+
+// Stack is an inventory slot, Count 0 for an empty one
+type Stack struct {
+	ID      string `json:"id,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Type    int    `json:"type,omitempty"`
+	Texture string `json:"texture,omitempty"`
+	Count   int    `json:"count"`
+}
+
+// Gun is the ammo left in one of the player's guns
+type Gun struct {
+	Name    string `json:"name"`
+	Ammo    int    `json:"ammo"`
+	Reserve int    `json:"reserve"`
+}
+
+// Buff is a timed buff the player is under
+type Buff struct {
+	Name      string  `json:"name"`
+	Kind      int     `json:"kind"`
+	Amount    float32 `json:"amount"`
+	Duration  float32 `json:"duration"`
+	Remaining float32 `json:"remaining"`
+}
+
+// Zombie is a zombie still alive. Stats are saved since waves make zombies
+// tougher than their archetype.
+type Zombie struct {
+	Type         int        `json:"type"`
+	Position     rl.Vector2 `json:"position"`
+	FacingRight  bool       `json:"facingRight"`
+	Health       int        `json:"health"`
+	AttackDamage float64    `json:"attackDamage"`
+	RoamSpeed    float32    `json:"roamSpeed"`
+	ChaseSpeed   float32    `json:"chaseSpeed"`
+	FollowRange  float32    `json:"followRange"`
+	Spawned      bool       `json:"spawned"` // Part of the current wave rather than placed by the level
+}
+
+// WorldItem is an item lying in the level
+type WorldItem struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Type     int        `json:"type"`
+	Texture  string     `json:"texture"`
+	Count    int        `json:"count"`
+	Position rl.Vector2 `json:"position"`
+	Velocity rl.Vector2 `json:"velocity"`
+}
+
+// Waves is how far the level's waves have got
+type Waves struct {
+	Wave    int     `json:"wave"`
+	Queue   []int   `json:"queue"` // Zombie types still to spawn this wave
+	Timer   float32 `json:"timer"`
+	InBreak bool    `json:"inBreak"`
+	Done    bool    `json:"done"`
+}
+
+// migrations upgrade a decoded save from the version it's keyed by to the
+// next one. Version 1 is the first format, so there's nothing to upgrade yet.
+var migrations = map[int]func(save map[string]any) error{}
+
+// SlotPath returns the file a slot is saved to
+func SlotPath(slot string) string {
+	return filepath.Join(Dir, slot+".json")
+}
+
+// Write saves f to slot, stamping it with the current version and time. The
+// file is replaced in one go, so a crash mid-save leaves the old save intact.
+func Write(slot string, f *File) error {
+	f.Version = Version
+	f.SavedAt = time.Now()
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(Dir, 0o755); err != nil {
+		return err
+	}
+	path := SlotPath(slot)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Read loads the save in slot, upgrading it from older versions
+func Read(slot string) (*File, error) {
+	path := SlotPath(slot)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoSave
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err = migrate(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// migrate brings a save written by any earlier version up to Version
+func migrate(data []byte) ([]byte, error) {
+	var save map[string]any
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, err
+	}
+	version, _ := save["version"].(float64) // JSON numbers decode as float64
+	if int(version) > Version {
+		return nil, fmt.Errorf("saved by a newer version of the game (format %d, this build reads up to %d)", int(version), Version)
+	}
+	if int(version) == Version {
+		return data, nil
+	}
+	for v := int(version); v < Version; v++ {
+		upgrade, ok := migrations[v]
+		if !ok {
+			return nil, fmt.Errorf("no migration from save format %d", v)
+		}
+		if err := upgrade(save); err != nil {
+			return nil, fmt.Errorf("migrating from save format %d: %w", v, err)
+		}
+		save["version"] = v + 1
+	}
+	return json.Marshal(save)
+}
+
+// Slot describes a saved slot, for listing saves without loading them
+type Slot struct {
+	Name    string
+	LevelID string
+	SavedAt time.Time
+}
+
+// Slots lists every saved slot, most recent first
+func Slots() ([]Slot, error) {
+	paths, err := filepath.Glob(filepath.Join(Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var slots []Slot
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		f, err := Read(name)
+		if err != nil {
+			continue // Unreadable saves aren't offered
+		}
+		slots = append(slots, Slot{Name: name, LevelID: f.LevelID, SavedAt: f.SavedAt})
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].SavedAt.After(slots[j].SavedAt) })
+	return slots, nil
+}
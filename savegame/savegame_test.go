@@ -0,0 +1,93 @@
+package savegame
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	// Pretend format 0 called the level "level" before it became "levelId"
+	renameLevel := func(save map[string]any) error {
+		save["levelId"] = save["level"]
+		delete(save, "level")
+		return nil
+	}
+	failing := func(save map[string]any) error {
+		return errors.New("broken")
+	}
+
+	tests := []struct {
+		name       string
+		data       string
+		migrations map[int]func(save map[string]any) error
+		want       map[string]any
+		wantErr    string
+	}{
+		{
+			name: "current version is left alone",
+			data: fmt.Sprintf(`{"version": %d, "levelId": "one"}`, Version),
+			want: map[string]any{"version": float64(Version), "levelId": "one"},
+		},
+		{
+			name:       "older version is upgraded",
+			data:       `{"version": 0, "level": "one"}`,
+			migrations: map[int]func(save map[string]any) error{0: renameLevel},
+			want:       map[string]any{"version": float64(1), "levelId": "one"},
+		},
+		{
+			name:       "no version counts as format 0",
+			data:       `{"level": "one"}`,
+			migrations: map[int]func(save map[string]any) error{0: renameLevel},
+			want:       map[string]any{"version": float64(1), "levelId": "one"},
+		},
+		{
+			name:    "newer version",
+			data:    fmt.Sprintf(`{"version": %d}`, Version+1),
+			wantErr: "newer version",
+		},
+		{
+			name:    "missing migration",
+			data:    `{"version": 0}`,
+			wantErr: "no migration from save format 0",
+		},
+		{
+			name:       "failing migration",
+			data:       `{"version": 0}`,
+			migrations: map[int]func(save map[string]any) error{0: failing},
+			wantErr:    "migrating from save format 0: broken",
+		},
+		{
+			name:    "not JSON",
+			data:    `{"version":`,
+			wantErr: "unexpected end",
+		},
+	}
+
+	defer func(saved map[int]func(save map[string]any) error) { migrations = saved }(migrations)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations = tt.migrations
+			data, err := migrate([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}